@@ -8,8 +8,8 @@ import (
 
 	"github.com/golang/freetype/truetype"
 	"github.com/llgcode/draw2d"
-
-	"github.com/rockwell-uk/go-text/fonts/ttf"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/rockwell-uk/csync/mutex"
 )
 
 type MyFontCache map[string]*truetype.Font
@@ -41,21 +41,29 @@ func (fc MyFontCache) Load(fd draw2d.FontData) (*truetype.Font, error) {
 	return font, nil
 }
 
-func init() {
-	fontCache := MyFontCache{}
+// DefaultRegistry is the registry installed by UseBundledFonts, nil until it is called.
+var DefaultRegistry *Registry
 
-	TTFs := map[string]([]byte){
-		"regular": ttf.Univers,
-		"bold":    ttf.UniversBold,
+// UseBundledFonts makes a registry of the bundled fonts draw2d's global font cache, so graphic
+// contexts made after it is called can load them. Importing the package used to do this, now
+// it has to be asked for. Applications with their own font cache should use NewBundledRegistry
+// and Attach it to their graphic contexts instead.
+func UseBundledFonts() (*Registry, error) {
+	registry, err := NewBundledRegistry()
+	if err != nil {
+		return nil, err
 	}
 
-	for fontName, TTF := range TTFs {
-		font, err := truetype.Parse(TTF)
-		if err != nil {
-			panic(err)
-		}
-		fontCache.Store(draw2d.FontData{Name: fontName}, font)
-	}
+	mutex.Lock()
+	DefaultRegistry = registry
+	mutex.Unlock()
+
+	draw2d.SetFontCache(registry)
+
+	return registry, nil
+}
 
-	draw2d.SetFontCache(fontCache)
+// Attach makes the registry the font cache gc loads its fonts from.
+func (r *Registry) Attach(gc *draw2dimg.GraphicContext) {
+	gc.FontCache = r
 }
@@ -0,0 +1,253 @@
+package fonts
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"golang.org/x/image/font/sfnt"
+)
+
+// the tags the different kinds of font file start with.
+const (
+	tagCollection = "ttcf"
+	tagWOFF       = "wOFF"
+	tagWOFF2      = "wOF2"
+)
+
+// maxFontSize is the most a WOFF or WOFF2 font can unpack to, so a small file cant claim
+// gigabytes of tables. The largest fonts, of CJK glyphs, are a few tens of megabytes.
+const maxFontSize = 1 << 27
+
+func isCollection(src []byte) bool {
+	return len(src) >= 4 && string(src[:4]) == tagCollection
+}
+
+// unpack returns the sfnt data of a WOFF or WOFF2 font, other fonts are returned as they are.
+func unpack(src []byte) ([]byte, error) {
+	if len(src) < 4 {
+		return src, nil
+	}
+
+	switch string(src[:4]) {
+	case tagWOFF:
+		return unpackWOFF(src)
+	case tagWOFF2:
+		return unpackWOFF2(src)
+	}
+
+	return src, nil
+}
+
+// unpackWOFF decompresses the tables of a WOFF font back into the font it was made from.
+// https://www.w3.org/TR/WOFF/
+func unpackWOFF(src []byte) ([]byte, error) {
+	const headerSize, entrySize = 44, 20
+
+	if len(src) < headerSize {
+		return nil, fmt.Errorf("%w (woff header is too short)", ErrFontNotSupported)
+	}
+
+	numTables := int(binary.BigEndian.Uint16(src[12:]))
+	if headerSize+numTables*entrySize > len(src) {
+		return nil, fmt.Errorf("%w (woff table directory is too short)", ErrFontNotSupported)
+	}
+
+	// check the sizes the tables say they are before unpacking any of them
+	var size int
+	for i := 0; i < numTables; i++ {
+		size += int(binary.BigEndian.Uint32(src[headerSize+i*entrySize+12:]))
+	}
+	if size > maxFontSize {
+		return nil, fmt.Errorf("%w (woff tables are too large)", ErrFontNotSupported)
+	}
+
+	tables := make([]sfntTable, numTables)
+	font := sfntFont{flavor: src[4:8]}
+
+	for i := range tables {
+		entry := src[headerSize+i*entrySize:]
+		offset := int(binary.BigEndian.Uint32(entry[4:]))
+		compLength := int(binary.BigEndian.Uint32(entry[8:]))
+		origLength := int(binary.BigEndian.Uint32(entry[12:]))
+		if offset < 0 || compLength < 0 || offset+compLength > len(src) {
+			return nil, fmt.Errorf("%w (woff table %q is out of bounds)", ErrFontNotSupported, entry[:4])
+		}
+
+		data := src[offset : offset+compLength]
+		if compLength < origLength {
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			// one more byte than it should be, so tables that are too long are caught
+			data, err = io.ReadAll(io.LimitReader(zr, int64(origLength)+1))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(data) != origLength {
+			return nil, fmt.Errorf("%w (woff table %q is the wrong length)", ErrFontNotSupported, entry[:4])
+		}
+
+		tables[i] = sfntTable{
+			tag:  string(entry[:4]),
+			data: data,
+		}
+		font.tables = append(font.tables, i)
+	}
+
+	return writeSFNT([]sfntFont{font}, tables, false), nil
+}
+
+// sfntTable is one of the tables of a font being put back together.
+type sfntTable struct {
+	tag  string
+	data []byte
+}
+
+// sfntFont is one of the fonts being put back together, with the indexes of its tables.
+type sfntFont struct {
+	flavor []byte
+	tables []int
+}
+
+// writeSFNT puts the tables back together into a font, or into a collection of fonts which
+// can share tables. The table directories come first, then the tables on four byte boundaries.
+func writeSFNT(fonts []sfntFont, tables []sfntTable, collection bool) []byte {
+	var size int
+	if collection {
+		size = 12 + 4*len(fonts)
+	}
+
+	dirs := make([]int, len(fonts))
+	for i, f := range fonts {
+		dirs[i] = size
+		size += 12 + 16*len(f.tables)
+	}
+
+	out := make([]byte, size)
+	if collection {
+		copy(out, tagCollection)
+		binary.BigEndian.PutUint32(out[4:], 0x00010000)
+		binary.BigEndian.PutUint32(out[8:], uint32(len(fonts)))
+		for i, dir := range dirs {
+			binary.BigEndian.PutUint32(out[12+4*i:], uint32(dir))
+		}
+	}
+
+	offsets := make([]int, len(tables))
+	for i, t := range tables {
+		offsets[i] = len(out)
+		out = append(out, t.data...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+
+	for i, f := range fonts {
+		// the directory is sorted by tag
+		sorted := append([]int{}, f.tables...)
+		sort.Slice(sorted, func(a, b int) bool {
+			return tables[sorted[a]].tag < tables[sorted[b]].tag
+		})
+
+		numTables := len(sorted)
+		searchRange, entrySelector := 1, 0
+		for searchRange*2 <= numTables {
+			searchRange *= 2
+			entrySelector++
+		}
+
+		dir := out[dirs[i]:]
+		copy(dir, f.flavor)
+		binary.BigEndian.PutUint16(dir[4:], uint16(numTables))
+		binary.BigEndian.PutUint16(dir[6:], uint16(searchRange*16))
+		binary.BigEndian.PutUint16(dir[8:], uint16(entrySelector))
+		binary.BigEndian.PutUint16(dir[10:], uint16((numTables-searchRange)*16))
+
+		for j, t := range sorted {
+			entry := dir[12+16*j:]
+			copy(entry, tables[t].tag)
+			binary.BigEndian.PutUint32(entry[4:], tableChecksum(tables[t]))
+			binary.BigEndian.PutUint32(entry[8:], uint32(offsets[t]))
+			binary.BigEndian.PutUint32(entry[12:], uint32(len(tables[t].data)))
+		}
+	}
+
+	return out
+}
+
+// tableChecksum adds up the table as big endian uint32s, leaving out the
+// checksum adjustment of the head table.
+func tableChecksum(t sfntTable) uint32 {
+	var sum uint32
+	for i := 0; i < len(t.data); i += 4 {
+		if t.tag == "head" && i == 8 {
+			continue
+		}
+
+		var word [4]byte
+		copy(word[:], t.data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+
+	return sum
+}
+
+// collectionOffsets returns where the table directory of each font in a collection is.
+func collectionOffsets(src []byte) []int {
+	if !isCollection(src) || len(src) < 12 {
+		return nil
+	}
+
+	n := int(binary.BigEndian.Uint32(src[8:]))
+	if 12+4*n > len(src) {
+		return nil
+	}
+
+	offsets := make([]int, n)
+	for i := range offsets {
+		offsets[i] = int(binary.BigEndian.Uint32(src[12+4*i:]))
+	}
+
+	return offsets
+}
+
+// registerCollection adds every font in a collection, they can only be used with BackendOpenType.
+func (r *Registry) registerCollection(src []byte) ([]RegisteredFont, error) {
+	c, err := sfnt.ParseCollection(src)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := collectionOffsets(src)
+	if len(offsets) != c.NumFonts() {
+		return nil, fmt.Errorf("%w (collection header)", ErrFontNotSupported)
+	}
+
+	fonts := []RegisteredFont{}
+	for i, dir := range offsets {
+		otf, err := c.Font(i)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := readFontInfo(src, dir)
+		if err != nil {
+			return nil, err
+		}
+
+		// freetype only reads the first font of a collection
+		f, err := r.register(info.Family, info.Generic, info.Style, info.Weight, src, dir, nil, otf)
+		if err != nil {
+			return fonts, err
+		}
+		fonts = append(fonts, f)
+	}
+
+	return fonts, nil
+}
@@ -0,0 +1,115 @@
+package fonts
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/llgcode/draw2d"
+	"github.com/rockwell-uk/csync/mutex"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+var cache_kerning = make(map[string]float64)
+
+// kernFace adds GPOS pair kerning to a face. freetype only reads the
+// legacy kern table, which many modern fonts (including Univers) dont have.
+type kernFace struct {
+	font.Face
+	sf   *sfnt.Font
+	ppem fixed.Int26_6
+}
+
+func (f *kernFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	k := f.Face.Kern(r0, r1)
+	if k != 0 {
+		return k
+	}
+
+	var b sfnt.Buffer
+
+	x0, err := f.sf.GlyphIndex(&b, r0)
+	if err != nil || x0 == 0 {
+		return 0
+	}
+
+	x1, err := f.sf.GlyphIndex(&b, r1)
+	if err != nil || x1 == 0 {
+		return 0
+	}
+
+	k, err = f.sf.Kern(&b, x0, x1, f.ppem, font.HintingNone)
+	if err != nil {
+		return 0
+	}
+
+	return k
+}
+
+// WithKerning wraps face so that Kern also consults the GPOS table of the
+// font src, which must be the font face was created from at the given size.
+//
+//nolint:ireturn,nolintlint
+func WithKerning(face font.Face, src []byte, size float64) (font.Face, error) {
+	sf, err := sfnt.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kernFace{
+		Face: face,
+		sf:   sf,
+		ppem: fixed.Int26_6(math.Round(size * 64)),
+	}, nil
+}
+
+// GetKerning returns the horizontal adjustment to apply between left and
+// right when they are drawn next to each other. A negative kern moves the
+// glyphs closer together.
+func GetKerning(tf TypeFace, left, right rune) float64 {
+	if tf.DisableKerning || tf.Face == nil {
+		return 0
+	}
+
+	cacheKey, ok := kernCacheKey(tf, left, right)
+	if ok {
+		mutex.Lock()
+		cachedVersion, exists := cache_kerning[cacheKey]
+		mutex.Unlock()
+		if exists {
+			return cachedVersion
+		}
+	}
+
+	k := math.Round(unfix(tf.Face.Kern(left, right))*100) / 100
+
+	if ok {
+		mutex.Lock()
+		cache_kerning[cacheKey] = k
+		mutex.Unlock()
+	}
+
+	return k
+}
+
+// fontSource returns the raw data of the font for fd, so faces can read its GPOS table.
+// Only a Registry keeps the data its fonts were parsed from.
+func fontSource(cache draw2d.FontCache, fd draw2d.FontData) []byte {
+	r, ok := cache.(*Registry)
+	if !ok {
+		return nil
+	}
+
+	f, _ := r.Lookup(fd)
+
+	return f.Source
+}
+
+func kernCacheKey(typeFace TypeFace, left, right rune) (string, bool) {
+	if typeFace.Name == "" {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s%s.%v.%s%s", typeFace.Name, variationsKey(typeFace.Variations), typeFace.Size, string(left), string(right)), true
+}
@@ -0,0 +1,185 @@
+package fonts
+
+import (
+	"math"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestGetKerning(t *testing.T) {
+	tests := map[string]struct {
+		ttf      []byte
+		gpos     bool
+		disable  bool
+		pair     string
+		fontSize float64
+		expected float64
+	}{
+		"kern table": {
+			ttf.ArialBold,
+			false,
+			false,
+			"AV",
+			float64(34),
+			-2.53,
+		},
+		"kern table disabled": {
+			ttf.ArialBold,
+			false,
+			true,
+			"AV",
+			float64(34),
+			0,
+		},
+		"no kern table": {
+			ttf.UniversBold,
+			false,
+			false,
+			"AV",
+			float64(34),
+			0,
+		},
+		"gpos": {
+			ttf.UniversBold,
+			true,
+			false,
+			"AV",
+			float64(34),
+			-1.88,
+		},
+		"gpos To": {
+			ttf.UniversBold,
+			true,
+			false,
+			"To",
+			float64(34),
+			-3.41,
+		},
+		"gpos unkerned pair": {
+			ttf.UniversBold,
+			true,
+			false,
+			"oo",
+			float64(34),
+			0,
+		},
+		"gpos disabled": {
+			ttf.UniversBold,
+			true,
+			true,
+			"Wa",
+			float64(34),
+			0,
+		},
+	}
+
+	for name, tt := range tests {
+		f, err := truetype.Parse(tt.ttf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opts := truetype.Options{
+			Size: tt.fontSize,
+		}
+		face := truetype.NewFace(f, &opts)
+
+		if tt.gpos {
+			face, err = WithKerning(face, tt.ttf, tt.fontSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		typeFace := TypeFace{
+			Size: tt.fontSize,
+			FontData: draw2d.FontData{
+				Name:   "bold",
+				Family: draw2d.FontFamilySans,
+				Style:  draw2d.FontStyleNormal,
+			},
+			Face:           face,
+			DisableKerning: tt.disable,
+		}
+
+		pair := []rune(tt.pair)
+		actual := GetKerning(typeFace, pair[0], pair[1])
+
+		if tt.expected != actual {
+			t.Errorf("%v: Expected [%v], Got [%v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestGetTextWidthKerning(t *testing.T) {
+	f, err := truetype.Parse(ttf.ArialBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 34,
+	}
+
+	typeFace := TypeFace{
+		Size: 34,
+		FontData: draw2d.FontData{
+			Name:   "bold",
+			Family: draw2d.FontFamilySans,
+			Style:  draw2d.FontStyleNormal,
+		},
+		Face: truetype.NewFace(f, &opts),
+	}
+
+	kerned := GetTextWidth(typeFace, "AVA")
+	kerning := GetKerning(typeFace, 'A', 'V') + GetKerning(typeFace, 'V', 'A')
+
+	typeFace.DisableKerning = true
+	unkerned := GetTextWidth(typeFace, "AVA")
+
+	// allow for rounding to 2 decimal places
+	expected := unkerned + kerning
+	if math.Abs(expected-kerned) > 0.015 {
+		t.Errorf("Expected [%v], Got [%v]", expected, kerned)
+	}
+}
+
+func TestGetKerningRegistry(t *testing.T) {
+	registry, err := NewBundledRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bold, err := registry.Load(draw2d.FontData{Name: "univers-bold"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// stored again under another name it keeps its gpos kerning, a cache without the font data doesnt have any
+	registry.Store(draw2d.FontData{Name: "stored"}, bold)
+	caches := map[string]draw2d.FontCache{
+		"univers-bold": registry,
+		"stored":       registry,
+		"bold":         MyFontCache{"bold": bold},
+	}
+	expected := map[string]float64{
+		"univers-bold": -1.88,
+		"stored":       -1.88,
+		"bold":         0,
+	}
+
+	for name, cache := range caches {
+		typeFace, err := ResizeTypeFace(TypeFace{Name: "kerning-registry-" + name, FontData: draw2d.FontData{Name: name}, FontCache: cache}, 34)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if actual := GetKerning(typeFace, 'A', 'V'); actual != expected[name] {
+			t.Errorf("%v: Expected [%v], Got [%v]", name, expected[name], actual)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package fonts
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/llgcode/draw2d"
+)
+
+// ErrFontNotSupported is returned for fonts that cant be registered.
+var ErrFontNotSupported = errors.New("the font isnt supported")
+
+// the file extensions LoadFS picks up.
+var fontExtensions = map[string]bool{
+	".ttf":   true,
+	".otf":   true,
+	".ttc":   true,
+	".otc":   true,
+	".woff":  true,
+	".woff2": true,
+}
+
+// the name table entries used for the family and style.
+const (
+	nameIDFamily               = 1
+	nameIDSubfamily            = 2
+	nameIDTypographicFamily    = 16
+	nameIDTypographicSubfamily = 17
+)
+
+// words in a subfamily that say what weight or slope the font is, the rest are about its width.
+var styleWords = map[string]bool{
+	"regular": true, "normal": true, "book": true, "roman": true, "plain": true,
+	"italic": true, "oblique": true, "slanted": true,
+	"thin": true, "hairline": true, "light": true, "medium": true, "bold": true,
+	"black": true, "heavy": true, "semi": true, "demi": true, "extra": true, "ultra": true,
+	"extralight": true, "ultralight": true, "semibold": true, "demibold": true,
+	"extrabold": true, "ultrabold": true, "extrablack": true, "ultrablack": true,
+}
+
+// FontInfo is what a font says about itself in its name, OS/2 and post tables.
+type FontInfo struct {
+	Family    string
+	Subfamily string
+	Generic   draw2d.FontFamily
+	Style     draw2d.FontStyle
+	Weight    Weight
+}
+
+// ReadFontInfo reads the family and style of the font src from its tables. The typographic
+// family is used when there is one so the weights of a family are grouped together, and the
+// generic family is guessed from the panose classification, sans serif if it doesnt say.
+func ReadFontInfo(src []byte) (FontInfo, error) {
+	return readFontInfo(src, 0)
+}
+
+// readFontInfo reads the font whose table directory is at dir in src.
+func readFontInfo(src []byte, dir int) (FontInfo, error) {
+	names := fontTable(src, dir, "name")
+	if names == nil {
+		return FontInfo{}, fmt.Errorf("%w (no name table)", ErrFontNotSupported)
+	}
+
+	name := func(ids ...uint16) string {
+		for _, id := range ids {
+			if s := strings.TrimSpace(fontName(names, id)); s != "" {
+				return s
+			}
+		}
+		return ""
+	}
+
+	info := FontInfo{
+		Family:    name(nameIDTypographicFamily, nameIDFamily),
+		Subfamily: name(nameIDTypographicSubfamily, nameIDSubfamily),
+		Generic:   draw2d.FontFamilySans,
+		Weight:    WeightRegular,
+	}
+	if info.Family == "" {
+		return FontInfo{}, fmt.Errorf("%w (no family name)", ErrFontNotSupported)
+	}
+
+	// the typographic family leaves the width out, eg Arial with a subfamily of Narrow Bold
+	if width := widthWords(info.Subfamily); width != "" && !strings.HasSuffix(info.Family, width) {
+		info.Family += " " + width
+	}
+
+	subfamily := strings.ToLower(info.Subfamily)
+	if strings.Contains(subfamily, "italic") || strings.Contains(subfamily, "oblique") {
+		info.Style |= draw2d.FontStyleItalic
+	}
+	if strings.Contains(subfamily, "bold") {
+		info.Weight = WeightBold
+	}
+
+	if os2 := fontTable(src, dir, "OS/2"); len(os2) >= 64 {
+		if w := Weight(binary.BigEndian.Uint16(os2[4:])); w > 0 && w <= 1000 {
+			info.Weight = w
+		}
+
+		fsSelection := binary.BigEndian.Uint16(os2[62:])
+		if fsSelection&1 != 0 {
+			info.Style |= draw2d.FontStyleItalic
+		}
+
+		// panose family kind 2 is latin text, its serif style 11 and up are sans serif
+		if panose := os2[32:42]; panose[0] == 2 && panose[1] >= 2 && panose[1] <= 10 {
+			info.Generic = draw2d.FontFamilySerif
+		}
+	}
+
+	if post := fontTable(src, dir, "post"); len(post) >= 16 && binary.BigEndian.Uint32(post[12:]) != 0 {
+		info.Generic = draw2d.FontFamilyMono
+	}
+
+	return info, nil
+}
+
+// RegisterSource adds the font src to the registry, under the family, style and weight it
+// gives itself. WOFF and WOFF2 fonts are unpacked first, collections have to use RegisterFonts.
+func (r *Registry) RegisterSource(src []byte) (RegisteredFont, error) {
+	src, err := unpack(src)
+	if err != nil {
+		return RegisteredFont{}, err
+	}
+	if isCollection(src) {
+		return RegisteredFont{}, fmt.Errorf("%w (a collection, use RegisterFonts)", ErrFontNotSupported)
+	}
+
+	info, err := ReadFontInfo(src)
+	if err != nil {
+		return RegisteredFont{}, err
+	}
+
+	return r.Register(info.Family, info.Generic, info.Style, info.Weight, src)
+}
+
+// RegisterFonts adds the font src to the registry, or every font in it if it is a collection.
+func (r *Registry) RegisterFonts(src []byte) ([]RegisteredFont, error) {
+	src, err := unpack(src)
+	if err != nil {
+		return nil, err
+	}
+	if !isCollection(src) {
+		f, err := r.RegisterSource(src)
+		if err != nil {
+			return nil, err
+		}
+		return []RegisteredFont{f}, nil
+	}
+
+	return r.registerCollection(src)
+}
+
+// LoadFile registers the font in the file at name, or every font in it if it is a collection.
+func (r *Registry) LoadFile(name string) ([]RegisteredFont, error) {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	fonts, err := r.RegisterFonts(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return fonts, nil
+}
+
+// LoadDir registers every font in dir and the directories below it.
+func (r *Registry) LoadDir(dir string) ([]RegisteredFont, error) {
+	return r.LoadFS(os.DirFS(dir), ".")
+}
+
+// LoadFS registers every font in root and the directories below it in fsys, which can be
+// an embed.FS. Files are picked by their extension, in lexical order. Files that cant be
+// read or registered dont stop the others loading, they are returned as LoadErrors.
+func (r *Registry) LoadFS(fsys fs.FS, root string) ([]RegisteredFont, error) {
+	loaded := []RegisteredFont{}
+	var errs LoadErrors
+
+	err := fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if name == root {
+				return err
+			}
+			errs = append(errs, err)
+			return nil
+		}
+		if d.IsDir() || !fontExtensions[strings.ToLower(path.Ext(name))] {
+			return nil
+		}
+
+		src, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+
+		// a collection can have registered some of its fonts
+		fonts, err := r.RegisterFonts(src)
+		loaded = append(loaded, fonts...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return loaded, err
+	}
+	if len(errs) > 0 {
+		return loaded, errs
+	}
+
+	return loaded, nil
+}
+
+// LoadErrors are the errors for the files LoadFS couldnt register.
+type LoadErrors []error
+
+func (e LoadErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether any of the errors is target, so errors.Is works on them.
+func (e LoadErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// widthWords returns the words of the subfamily that arent about weight or slope.
+func widthWords(subfamily string) string {
+	words := []string{}
+	for _, w := range strings.FieldsFunc(subfamily, func(r rune) bool { return r == ' ' || r == '-' }) {
+		if !styleWords[strings.ToLower(w)] {
+			words = append(words, w)
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// fontName returns the name with the given id from the name table, preferring the windows
+// english names, "" if there isnt one.
+func fontName(table []byte, id uint16) string {
+	if len(table) < 6 {
+		return ""
+	}
+
+	count := int(binary.BigEndian.Uint16(table[2:]))
+	storage := int(binary.BigEndian.Uint16(table[4:]))
+
+	var found string
+	for i := 0; i < count; i++ {
+		record := 6 + 12*i
+		if record+12 > len(table) {
+			break
+		}
+		if binary.BigEndian.Uint16(table[record+6:]) != id {
+			continue
+		}
+
+		platform := binary.BigEndian.Uint16(table[record:])
+		language := binary.BigEndian.Uint16(table[record+4:])
+		length := int(binary.BigEndian.Uint16(table[record+8:]))
+		offset := storage + int(binary.BigEndian.Uint16(table[record+10:]))
+		if offset+length > len(table) {
+			continue
+		}
+		b := table[offset : offset+length]
+
+		var s string
+		switch platform {
+		case 0, 3:
+			// utf-16
+			u := make([]uint16, len(b)/2)
+			for j := range u {
+				u[j] = binary.BigEndian.Uint16(b[2*j:])
+			}
+			s = string(utf16.Decode(u))
+		case 1:
+			s = string(b)
+		default:
+			continue
+		}
+
+		if platform == 3 && language == 0x409 {
+			return s
+		}
+		if found == "" {
+			found = s
+		}
+	}
+
+	return found
+}
+
+// fontTable returns the table with the given tag from the font whose table directory is at
+// dir in src, nil if it hasnt got one.
+func fontTable(src []byte, dir int, tag string) []byte {
+	if dir < 0 || dir+12 > len(src) {
+		return nil
+	}
+
+	tables := int(binary.BigEndian.Uint16(src[dir+4:]))
+	for i := 0; i < tables; i++ {
+		entry := dir + 12 + 16*i
+		if entry+16 > len(src) {
+			return nil
+		}
+		if string(src[entry:entry+4]) != tag {
+			continue
+		}
+
+		offset := int(binary.BigEndian.Uint32(src[entry+8:]))
+		length := int(binary.BigEndian.Uint32(src[entry+12:]))
+		if offset < 0 || length < 0 || offset+length > len(src) {
+			return nil
+		}
+
+		return src[offset : offset+length]
+	}
+
+	return nil
+}
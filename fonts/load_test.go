@@ -0,0 +1,130 @@
+package fonts
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/llgcode/draw2d"
+
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestReadFontInfo(t *testing.T) {
+	tests := map[string]struct {
+		src      []byte
+		expected FontInfo
+	}{
+		"Univers Bold": {
+			ttf.UniversBold,
+			FontInfo{Family: "Univers", Subfamily: "Bold", Generic: draw2d.FontFamilySans, Weight: WeightBold},
+		},
+		"Arial": {
+			ttf.Arial,
+			FontInfo{Family: "Arial", Subfamily: "Regular", Generic: draw2d.FontFamilySans, Weight: WeightRegular},
+		},
+		"Arial Narrow Bold": {
+			ttf.ArialNarrowBold,
+			FontInfo{Family: "Arial Narrow", Subfamily: "Narrow", Generic: draw2d.FontFamilySans, Weight: WeightBold},
+		},
+		"English Gothic": {
+			ttf.EnglishGothic,
+			FontInfo{Family: "English Gothic, 17th c.", Subfamily: "Regular", Generic: draw2d.FontFamilySans, Weight: WeightRegular},
+		},
+	}
+
+	for name, tt := range tests {
+		actual, err := ReadFontInfo(tt.src)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+
+	if _, err := ReadFontInfo([]byte("not a font")); !errors.Is(err, ErrFontNotSupported) {
+		t.Errorf("Expected [%v]\nActual [%v]", ErrFontNotSupported, err)
+	}
+}
+
+func TestLoadFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"fonts/univers/Univers.TTF":     {Data: ttf.Univers},
+		"fonts/univers/UniversBold.ttf": {Data: ttf.UniversBold},
+		"fonts/ArialNarrow.ttf":         {Data: ttf.ArialNarrow},
+		"fonts/README.md":               {Data: []byte("not a font")},
+		"other/Arial.ttf":               {Data: ttf.Arial},
+	}
+
+	registry := NewRegistry()
+	loaded, err := registry.LoadFS(fsys, "fonts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, f := range loaded {
+		names = append(names, f.Name)
+	}
+
+	expected := []string{"arial-narrow-regular", "univers-regular", "univers-bold"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected [%+v]\nActual [%+v]", expected, names)
+	}
+
+	f, found := registry.Lookup(draw2d.FontData{Family: draw2d.FontFamilySans, Style: draw2d.FontStyleBold})
+	if !found || f.Name != "univers-bold" {
+		t.Errorf("Expected [univers-bold]\nActual [%+v %v]", f.Name, found)
+	}
+
+	// the file that isnt a font doesnt stop the others loading
+	fsys["fonts/Broken.ttf"] = &fstest.MapFile{Data: []byte("not a font")}
+	loaded, err = NewRegistry().LoadFS(fsys, "fonts")
+
+	var errs LoadErrors
+	if !errors.As(err, &errs) || len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "fonts/Broken.ttf") {
+		t.Errorf("Expected an error for [fonts/Broken.ttf]\nActual [%v]", err)
+	}
+	if len(loaded) != len(expected) {
+		t.Errorf("Expected [%v] fonts\nActual [%+v]", len(expected), loaded)
+	}
+
+	if _, err = NewRegistry().LoadFS(fsys, "missing"); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "arial.ttf"), ttf.Arial, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	registry := NewRegistry()
+	loaded, err := registry.LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 1 || loaded[0].Name != "arial-regular" {
+		t.Errorf("Expected [arial-regular]\nActual [%+v]", loaded)
+	}
+
+	fonts, err := NewRegistry().LoadFile(filepath.Join(dir, "arial.ttf"))
+	if err != nil || len(fonts) != 1 || fonts[0].Family != "Arial" {
+		t.Errorf("Expected [Arial]\nActual [%+v %v]", fonts, err)
+	}
+
+	if _, err = registry.LoadFile(filepath.Join(dir, "arial.ttf")); !errors.Is(err, ErrFontRegistered) {
+		t.Errorf("Expected [%v]\nActual [%v]", ErrFontRegistered, err)
+	}
+
+	if _, err = registry.LoadFile(filepath.Join(dir, "missing.ttf")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
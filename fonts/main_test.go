@@ -0,0 +1,14 @@
+package fonts
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	if _, err := UseBundledFonts(); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,178 @@
+package fonts
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"github.com/rockwell-uk/go-draw/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Backend is the library a TypeFace's font is read and drawn with.
+type Backend int
+
+const (
+	// freetype, which reads truetype outlines and draws with the graphic context's font
+	BackendTrueType Backend = iota
+	// x/image, which reads any opentype font including cff outlines and collections,
+	// glyphs are drawn as paths
+	BackendOpenType
+)
+
+func (b Backend) String() string {
+	switch b {
+	case BackendTrueType:
+		return "truetype"
+	case BackendOpenType:
+		return "opentype"
+	}
+
+	return fmt.Sprintf("backend(%d)", int(b))
+}
+
+// OpenTypeCache is a font cache that can also load fonts for BackendOpenType, Registry is one.
+type OpenTypeCache interface {
+	draw2d.FontCache
+	LoadOpenType(fd draw2d.FontData) (*sfnt.Font, error)
+}
+
+// NewOpenTypeFace returns a face for the font at the given size, with the same resolution
+// and hinting as the freetype faces.
+//
+//nolint:ireturn,nolintlint
+func NewOpenTypeFace(f *sfnt.Font, size float64) (font.Face, error) {
+	return opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingNone,
+	})
+}
+
+// GetOpenTypeFace loads fontData from the cache and returns a face for it for BackendOpenType.
+//
+//nolint:ireturn,nolintlint
+func GetOpenTypeFace(cache draw2d.FontCache, fontData draw2d.FontData, size float64) (font.Face, error) {
+	f, err := loadOpenType(cache, fontData)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewOpenTypeFace(f, size)
+}
+
+// DrawRune draws char with its origin at pos, rotated by rotation degrees, using tf's backend.
+// The colours and stroke come from SetFont, as they do for text drawn by the graphic context.
+func DrawRune(gc *draw2dimg.GraphicContext, tf TypeFace, pos []float64, rotation float64, char rune) error {
+	if tf.Backend != BackendOpenType {
+		return draw.DrawRune(gc, pos, tf.Face, rotation, char)
+	}
+
+	cache := tf.FontCache
+	if cache == nil {
+		cache = gc.FontCache
+	}
+
+	f, err := loadOpenType(cache, tf.FontData)
+	if err != nil {
+		return err
+	}
+
+	var vf *variableFont
+	if len(tf.Variations) > 0 {
+		rf, err := loadRegistered(cache, tf.FontData)
+		if err != nil {
+			return err
+		}
+		vf = rf.variable
+	}
+
+	// the graphic context scales fonts by its resolution, so do the same
+	size := tf.Size * float64(gc.GetDPI()) / 72
+	path, err := glyphPath(f, vf, char, size, tf.Variations)
+	if err != nil {
+		return err
+	}
+
+	gc.Save()
+	defer gc.Restore()
+
+	gc.Translate(pos[0], pos[1])
+	gc.Rotate(rotation * (math.Pi / 180))
+	gc.SetFillRule(draw2d.FillRuleWinding)
+
+	if tf.StrokeStyle.Width > 0 && tf.StrokeStyle.Color != nil {
+		gc.Stroke(path)
+	}
+	gc.Fill(path)
+
+	return nil
+}
+
+// glyphPath returns the outline of char at size pixels per em, with its origin at 0, 0.
+// Variations need vf, the variable font tables of f.
+func glyphPath(f *sfnt.Font, vf *variableFont, char rune, size float64, variations []Variation) (*draw2d.Path, error) {
+	var b sfnt.Buffer
+
+	x, err := f.GlyphIndex(&b, char)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(variations) > 0 {
+		if vf == nil {
+			return nil, fmt.Errorf("%w (not a variable font with truetype outlines)", ErrFontNotSupported)
+		}
+
+		o, err := vf.outline(int(x), vf.normalise(variations), 0)
+		if err != nil {
+			return nil, err
+		}
+
+		path := &draw2d.Path{}
+		o.walk(size/vf.upem, path.MoveTo, path.LineTo, path.QuadCurveTo, path.Close)
+
+		return path, nil
+	}
+
+	segments, err := f.LoadGlyph(&b, x, fixed.Int26_6(math.Round(size*64)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	path := &draw2d.Path{}
+	for i, s := range segments {
+		a := s.Args
+		switch s.Op {
+		case sfnt.SegmentOpMoveTo:
+			if i > 0 {
+				path.Close()
+			}
+			path.MoveTo(unfix(a[0].X), unfix(a[0].Y))
+		case sfnt.SegmentOpLineTo:
+			path.LineTo(unfix(a[0].X), unfix(a[0].Y))
+		case sfnt.SegmentOpQuadTo:
+			path.QuadCurveTo(unfix(a[0].X), unfix(a[0].Y), unfix(a[1].X), unfix(a[1].Y))
+		case sfnt.SegmentOpCubeTo:
+			path.CubicCurveTo(unfix(a[0].X), unfix(a[0].Y), unfix(a[1].X), unfix(a[1].Y), unfix(a[2].X), unfix(a[2].Y))
+		}
+	}
+	if len(segments) > 0 {
+		path.Close()
+	}
+
+	return path, nil
+}
+
+func loadOpenType(cache draw2d.FontCache, fontData draw2d.FontData) (*sfnt.Font, error) {
+	otc, ok := cache.(OpenTypeCache)
+	if !ok {
+		return nil, fmt.Errorf("font %s: the font cache cant load fonts for %v", fontData.Name, BackendOpenType)
+	}
+
+	return otc.LoadOpenType(fontData)
+}
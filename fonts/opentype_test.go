@@ -0,0 +1,242 @@
+package fonts
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestOpenTypeBackend(t *testing.T) {
+	registry := NewRegistry()
+	univers, err := registry.Register("Univers", draw2d.FontFamilySans, draw2d.FontStyleBold, WeightBold, ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	face, err := GetOpenTypeFace(registry, univers.FontData(), 34)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	typeFaces := map[Backend]TypeFace{
+		BackendTrueType: {
+			Name:     "truetype",
+			Size:     34,
+			FontData: univers.FontData(),
+			Face:     newFace(univers.Font, univers.Source, 34),
+			Color:    color.RGBA{0x00, 0x00, 0x00, 0xFF},
+		},
+		BackendOpenType: {
+			Name:      "opentype",
+			Size:      34,
+			FontData:  univers.FontData(),
+			Face:      face,
+			Color:     color.RGBA{0x00, 0x00, 0x00, 0xFF},
+			FontCache: registry,
+			Backend:   BackendOpenType,
+		},
+	}
+
+	expected := GetTextWidth(typeFaces[BackendTrueType], "Mellor Street")
+	actual := GetTextWidth(typeFaces[BackendOpenType], "Mellor Street")
+	if math.Abs(expected-actual) > 0.01 {
+		t.Errorf("Expected width [%v]\nActual [%v]", expected, actual)
+	}
+
+	resized, err := ResizeTypeFace(typeFaces[BackendOpenType], 17)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := GetTextWidth(resized, "Mellor Street"); math.Abs(expected/2-a) > 1 {
+		t.Errorf("Expected resized width [%v]\nActual [%v]", expected/2, a)
+	}
+
+	// both backends should draw the same glyphs
+	dark := make(map[Backend]int)
+	for backend, tf := range typeFaces {
+		m := image.NewRGBA(image.Rect(0, 0, 300, 80))
+		draw.Draw(m, m.Bounds(), &image.Uniform{color.White}, image.Point{0, 0}, draw.Src)
+
+		gc := draw2dimg.NewGraphicContext(m)
+		registry.Attach(gc)
+		SetFont(gc, tf)
+
+		x := 10.0
+		for _, r := range "Mellor" {
+			if err := DrawRune(gc, tf, []float64{x, 50}, 10, r); err != nil {
+				t.Fatalf("%v: %v", backend, err)
+			}
+			x += GetGlyphWidth(tf, r)
+		}
+
+		for i := 0; i < len(m.Pix); i += 4 {
+			if m.Pix[i] < 0x80 {
+				dark[backend]++
+			}
+		}
+	}
+
+	if dark[BackendTrueType] == 0 || math.Abs(float64(dark[BackendTrueType]-dark[BackendOpenType])) > float64(dark[BackendTrueType])/50 {
+		t.Errorf("Expected [%v] dark pixels\nActual [%v]", dark[BackendTrueType], dark[BackendOpenType])
+	}
+}
+
+func TestRegisterWOFF(t *testing.T) {
+	registry := NewRegistry()
+	fonts, err := registry.RegisterFonts(packWOFF(t, ttf.Arial))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fonts) != 1 || fonts[0].Name != "arial-regular" || fonts[0].Font == nil || fonts[0].OpenType == nil {
+		t.Fatalf("Expected [arial-regular] for both backends\nActual [%+v]", fonts)
+	}
+
+	for _, tag := range []string{"name", "glyf", "cmap", "OS/2"} {
+		if !bytes.Equal(fontTable(fonts[0].Source, 0, tag), fontTable(ttf.Arial, 0, tag)) {
+			t.Errorf("Expected the %v table to be unpacked", tag)
+		}
+	}
+
+	woff2 := append([]byte("wOF2"), make([]byte, 40)...)
+	if _, err = registry.RegisterFonts(woff2); !errors.Is(err, ErrFontNotSupported) {
+		t.Errorf("Expected [%v]\nActual [%v]", ErrFontNotSupported, err)
+	}
+
+	// the first table unpacks to more than it says it does, or says it is too large to unpack
+	for _, change := range []int{-1, maxFontSize} {
+		woff := packWOFF(t, ttf.Arial)
+		entry := woff[44:]
+		binary.BigEndian.PutUint32(entry[12:], uint32(int(binary.BigEndian.Uint32(entry[12:]))+change))
+
+		if _, err = registry.RegisterFonts(woff); !errors.Is(err, ErrFontNotSupported) {
+			t.Errorf("%v: Expected [%v]\nActual [%v]", change, ErrFontNotSupported, err)
+		}
+	}
+}
+
+func TestRegisterCollection(t *testing.T) {
+	registry := NewRegistry()
+	fonts, err := registry.RegisterFonts(packCollection(t, ttf.Univers, ttf.UniversBold))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, f := range fonts {
+		names = append(names, f.Name)
+	}
+	if len(names) != 2 || names[0] != "univers-regular" || names[1] != "univers-bold" {
+		t.Fatalf("Expected [univers-regular univers-bold]\nActual [%+v]", names)
+	}
+
+	// freetype cant read collections
+	if _, err = registry.Load(fonts[1].FontData()); !errors.Is(err, ErrFontNotSupported) {
+		t.Errorf("Expected [%v]\nActual [%v]", ErrFontNotSupported, err)
+	}
+
+	face, err := GetOpenTypeFace(registry, fonts[1].FontData(), 34)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bold, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := GetGlyphWidth(TypeFace{Name: "bold", Size: 34, Face: newFace(bold, ttf.UniversBold, 34)}, 'M')
+	actual := GetGlyphWidth(TypeFace{Name: "collection", Size: 34, Face: face, Backend: BackendOpenType}, 'M')
+	if expected != actual {
+		t.Errorf("Expected [%v]\nActual [%v]", expected, actual)
+	}
+
+	if _, err = registry.RegisterSource(packCollection(t, ttf.Arial)); !errors.Is(err, ErrFontNotSupported) {
+		t.Errorf("Expected [%v]\nActual [%v]", ErrFontNotSupported, err)
+	}
+}
+
+// packWOFF compresses every table of the font src into a WOFF font.
+func packWOFF(t *testing.T, src []byte) []byte {
+	t.Helper()
+
+	numTables := int(binary.BigEndian.Uint16(src[4:]))
+	header := make([]byte, 44+20*numTables)
+	copy(header, "wOFF")
+	copy(header[4:], src[:4])
+	binary.BigEndian.PutUint16(header[12:], uint16(numTables))
+
+	data := []byte{}
+	for i := 0; i < numTables; i++ {
+		entry := src[12+16*i:]
+		offset := binary.BigEndian.Uint32(entry[8:])
+		length := binary.BigEndian.Uint32(entry[12:])
+
+		var b bytes.Buffer
+		zw := zlib.NewWriter(&b)
+		if _, err := zw.Write(src[offset : offset+length]); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		compressed := b.Bytes()
+		if len(compressed) >= int(length) {
+			compressed = src[offset : offset+length]
+		}
+
+		out := header[44+20*i:]
+		copy(out, entry[:4])
+		binary.BigEndian.PutUint32(out[4:], uint32(len(header)+len(data)))
+		binary.BigEndian.PutUint32(out[8:], uint32(len(compressed)))
+		binary.BigEndian.PutUint32(out[12:], length)
+		binary.BigEndian.PutUint32(out[16:], binary.BigEndian.Uint32(entry[4:]))
+
+		data = append(data, compressed...)
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+	}
+
+	return append(header, data...)
+}
+
+// packCollection puts the fonts into a collection, one after the other.
+func packCollection(t *testing.T, srcs ...[]byte) []byte {
+	t.Helper()
+
+	out := make([]byte, 12+4*len(srcs))
+	copy(out, "ttcf")
+	binary.BigEndian.PutUint32(out[4:], 0x00010000)
+	binary.BigEndian.PutUint32(out[8:], uint32(len(srcs)))
+
+	for i, src := range srcs {
+		start := len(out)
+		binary.BigEndian.PutUint32(out[12+4*i:], uint32(start))
+		out = append(out, src...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+
+		// the table offsets are from the start of the collection
+		numTables := int(binary.BigEndian.Uint16(src[4:]))
+		for j := 0; j < numTables; j++ {
+			entry := out[start+12+16*j:]
+			binary.BigEndian.PutUint32(entry[8:], binary.BigEndian.Uint32(entry[8:])+uint32(start))
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,380 @@
+package fonts
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	"golang.org/x/image/font/sfnt"
+
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+// ErrFontRegistered is returned when a font is registered under a name already in use.
+var ErrFontRegistered = errors.New("a font is already registered with that name")
+
+// Weight is how heavy a font is, on the usual 100 to 900 scale.
+type Weight int
+
+const (
+	WeightThin       Weight = 100
+	WeightExtraLight Weight = 200
+	WeightLight      Weight = 300
+	WeightRegular    Weight = 400
+	WeightMedium     Weight = 500
+	WeightSemiBold   Weight = 600
+	WeightBold       Weight = 700
+	WeightExtraBold  Weight = 800
+	WeightBlack      Weight = 900
+)
+
+var weightNames = map[Weight]string{
+	WeightThin:       "thin",
+	WeightExtraLight: "extra-light",
+	WeightLight:      "light",
+	WeightRegular:    "regular",
+	WeightMedium:     "medium",
+	WeightSemiBold:   "semi-bold",
+	WeightBold:       "bold",
+	WeightExtraBold:  "extra-bold",
+	WeightBlack:      "black",
+}
+
+func (w Weight) String() string {
+	if name, ok := weightNames[w]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("weight-%d", int(w))
+}
+
+// RegisteredFont is a font in a Registry.
+type RegisteredFont struct {
+	// the unique name the font is stored under, use it as draw2d.FontData.Name
+	Name string
+	// the family name, eg Arial Narrow
+	Family string
+	// the generic family the font falls back to
+	Generic draw2d.FontFamily
+	Style   draw2d.FontStyle
+	Weight  Weight
+	// the font for BackendTrueType, nil if freetype cant read it, eg cff outlines
+	Font *truetype.Font
+	// the font for BackendOpenType, nil if x/image cant read it
+	OpenType *sfnt.Font
+	// the raw font data, the whole collection for fonts from one
+	Source []byte
+	// the tables of a variable font with truetype outlines, nil for other fonts
+	variable *variableFont
+}
+
+// FontData returns the draw2d.FontData that loads this font from the registry.
+func (f RegisteredFont) FontData() draw2d.FontData {
+	return draw2d.FontData{
+		Name:   f.Name,
+		Family: f.Generic,
+		Style:  f.Style,
+	}
+}
+
+// Registry holds fonts by name, family, style and weight. It is a draw2d.FontCache
+// so it can be used as a graphic context's font cache. It is safe to use from several goroutines.
+type Registry struct {
+	mu    sync.RWMutex
+	fonts map[string]RegisteredFont
+	// the names in the order they were registered, earlier fonts are preferred
+	order []string
+	// other names fonts are known by
+	aliases map[string]string
+	// the font used when nothing else matches
+	fallback string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		fonts:   make(map[string]RegisteredFont),
+		aliases: make(map[string]string),
+	}
+}
+
+// FontName returns the name a font is registered under, eg arial-narrow-bold.
+func FontName(family string, weight Weight, style draw2d.FontStyle) string {
+	name := strings.ToLower(strings.Join(strings.Fields(family), "-")) + "-" + weight.String()
+	if style&draw2d.FontStyleItalic != 0 {
+		name += "-italic"
+	}
+
+	return name
+}
+
+// Register parses src and adds it to the registry under FontName(family, weight, style).
+// The first font registered is the fallback for lookups that dont match anything. A font
+// with the same name as one already registered isnt added, and ErrFontRegistered is returned.
+func (r *Registry) Register(family string, generic draw2d.FontFamily, style draw2d.FontStyle, weight Weight, src []byte) (RegisteredFont, error) {
+	font, err := truetype.Parse(src)
+	otf, _ := sfnt.Parse(src)
+	if err != nil {
+		if otf == nil {
+			return RegisteredFont{}, fmt.Errorf("font %s: %w", family, err)
+		}
+		font = nil
+	}
+
+	return r.register(family, generic, style, weight, src, 0, font, otf)
+}
+
+// register adds a font read by either or both backends to the registry, its table directory
+// is at dir in src.
+func (r *Registry) register(family string, generic draw2d.FontFamily, style draw2d.FontStyle, weight Weight, src []byte, dir int, font *truetype.Font, otf *sfnt.Font) (RegisteredFont, error) {
+	// the weight says whether the font is bold
+	if weight >= WeightBold {
+		style |= draw2d.FontStyleBold
+	} else {
+		style &^= draw2d.FontStyleBold
+	}
+
+	rf := RegisteredFont{
+		Name:     FontName(family, weight, style),
+		Family:   family,
+		Generic:  generic,
+		Style:    style,
+		Weight:   weight,
+		Font:     font,
+		OpenType: otf,
+		Source:   src,
+	}
+	if otf != nil {
+		rf.variable = parseVariableFont(src, dir)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.fonts[rf.Name]; exists {
+		return RegisteredFont{}, fmt.Errorf("font %s: %w", rf.Name, ErrFontRegistered)
+	}
+	r.add(rf)
+
+	return rf, nil
+}
+
+// Alias makes a registered font available under another name.
+func (r *Registry) Alias(alias, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.fonts[name]; !ok {
+		return fmt.Errorf("font %s is not registered", name)
+	}
+	r.aliases[alias] = name
+
+	return nil
+}
+
+// Fonts returns every registered font, sorted by name.
+func (r *Registry) Fonts() []RegisteredFont {
+	r.mu.RLock()
+	fonts := make([]RegisteredFont, 0, len(r.fonts))
+	for _, f := range r.fonts {
+		fonts = append(fonts, f)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(fonts, func(i, j int) bool {
+		return fonts[i].Name < fonts[j].Name
+	})
+
+	return fonts
+}
+
+// Families returns the family names of the registered fonts, sorted.
+func (r *Registry) Families() []string {
+	seen := make(map[string]bool)
+	families := []string{}
+
+	for _, f := range r.Fonts() {
+		if !seen[f.Family] {
+			seen[f.Family] = true
+			families = append(families, f.Family)
+		}
+	}
+	sort.Strings(families)
+
+	return families
+}
+
+// Lookup finds the font for fd. The font (or alias) named by fd is used if there is one, otherwise
+// the first registered font in the generic family is used with the same style, then without
+// italic, then regular. Failing that the same goes for any family, then the fallback font.
+// So draw2d's default font data, named luxi, gets a registered font in its family.
+func (r *Registry) Lookup(fd draw2d.FontData) (RegisteredFont, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if fd.Name != "" {
+		if f, ok := r.byName(fd.Name); ok {
+			return f, true
+		}
+	}
+
+	styles := []draw2d.FontStyle{
+		fd.Style,
+		fd.Style &^ draw2d.FontStyleItalic,
+		draw2d.FontStyleNormal,
+	}
+
+	for _, sameFamily := range []bool{true, false} {
+		for _, style := range styles {
+			for _, name := range r.order {
+				f := r.fonts[name]
+				if (!sameFamily || f.Generic == fd.Family) && f.Style == style {
+					return f, true
+				}
+			}
+		}
+	}
+
+	f, ok := r.fonts[r.fallback]
+
+	return f, ok
+}
+
+// LookupFamily finds a font by its family name, weight and style, ignoring case.
+func (r *Registry) LookupFamily(family string, weight Weight, style draw2d.FontStyle) (RegisteredFont, bool) {
+	if weight >= WeightBold {
+		style |= draw2d.FontStyleBold
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.byName(FontName(family, weight, style))
+}
+
+func (r *Registry) byName(name string) (RegisteredFont, bool) {
+	if alias, ok := r.aliases[name]; ok {
+		name = alias
+	}
+
+	f, ok := r.fonts[name]
+
+	return f, ok
+}
+
+// Load returns the font for fd, so the registry can be used as a draw2d.FontCache.
+func (r *Registry) Load(fd draw2d.FontData) (*truetype.Font, error) {
+	f, ok := r.Lookup(fd)
+	if !ok {
+		return nil, fmt.Errorf("font %s is not registered", fd.Name)
+	}
+	if f.Font == nil {
+		return nil, fmt.Errorf("font %s: %w by freetype, use BackendOpenType", f.Name, ErrFontNotSupported)
+	}
+
+	return f.Font, nil
+}
+
+// LoadOpenType returns the font for fd for BackendOpenType, it is an OpenTypeCache.
+func (r *Registry) LoadOpenType(fd draw2d.FontData) (*sfnt.Font, error) {
+	f, ok := r.Lookup(fd)
+	if !ok {
+		return nil, fmt.Errorf("font %s is not registered", fd.Name)
+	}
+	if f.OpenType == nil {
+		return nil, fmt.Errorf("font %s: %w by x/image, use BackendTrueType", f.Name, ErrFontNotSupported)
+	}
+
+	return f.OpenType, nil
+}
+
+// Store adds an already parsed font to the registry under fd.Name. It is how draw2d adds
+// fonts to its font cache, so it cant fail, a font already stored under the name is replaced.
+func (r *Registry) Store(fd draw2d.FontData, font *truetype.Font) {
+	weight := WeightRegular
+	if fd.Style&draw2d.FontStyleBold != 0 {
+		weight = WeightBold
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// a font that is already registered keeps its data, and so its GPOS kerning and variations
+	var src []byte
+	var variable *variableFont
+	for _, f := range r.fonts {
+		if f.Font == font {
+			src, variable = f.Source, f.variable
+			break
+		}
+	}
+	otf, _ := sfnt.Parse(src)
+
+	r.add(RegisteredFont{
+		Name:     fd.Name,
+		Family:   fd.Name,
+		Generic:  fd.Family,
+		Style:    fd.Style,
+		Weight:   weight,
+		Font:     font,
+		OpenType: otf,
+		Source:   src,
+		variable: variable,
+	})
+}
+
+func (r *Registry) add(f RegisteredFont) {
+	if _, exists := r.fonts[f.Name]; !exists {
+		r.order = append(r.order, f.Name)
+	}
+	r.fonts[f.Name] = f
+
+	if r.fallback == "" {
+		r.fallback = f.Name
+	}
+}
+
+// bundledFont is a font embedded in the ttf package.
+type bundledFont struct {
+	family  string
+	generic draw2d.FontFamily
+	style   draw2d.FontStyle
+	weight  Weight
+	src     []byte
+}
+
+// the bundled fonts, Univers first so it is the fallback.
+var bundledFonts = []bundledFont{
+	{"Univers", draw2d.FontFamilySans, draw2d.FontStyleNormal, WeightRegular, ttf.Univers},
+	{"Univers", draw2d.FontFamilySans, draw2d.FontStyleBold, WeightBold, ttf.UniversBold},
+	{"Arial", draw2d.FontFamilySans, draw2d.FontStyleNormal, WeightRegular, ttf.Arial},
+	{"Arial", draw2d.FontFamilySans, draw2d.FontStyleBold, WeightBold, ttf.ArialBold},
+	{"Arial Narrow", draw2d.FontFamilySans, draw2d.FontStyleNormal, WeightRegular, ttf.ArialNarrow},
+	{"Arial Narrow", draw2d.FontFamilySans, draw2d.FontStyleBold, WeightBold, ttf.ArialNarrowBold},
+	// blackletter, but its the closest thing to a serif font there is
+	{"English Gothic", draw2d.FontFamilySerif, draw2d.FontStyleNormal, WeightRegular, ttf.EnglishGothic},
+}
+
+// NewBundledRegistry returns a registry holding every font in the ttf package, with Univers
+// also available as "regular" and "bold" and used for the sans serif generic family.
+func NewBundledRegistry() (*Registry, error) {
+	r := NewRegistry()
+
+	for _, b := range bundledFonts {
+		if _, err := r.Register(b.family, b.generic, b.style, b.weight, b.src); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := r.Alias("regular", FontName("Univers", WeightRegular, draw2d.FontStyleNormal)); err != nil {
+		return nil, err
+	}
+	if err := r.Alias("bold", FontName("Univers", WeightBold, draw2d.FontStyleBold)); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
@@ -0,0 +1,208 @@
+package fonts
+
+import (
+	"errors"
+	"image"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestRegistryEnumerate(t *testing.T) {
+	registry, err := NewBundledRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, f := range registry.Fonts() {
+		names = append(names, f.Name)
+	}
+
+	expectedNames := []string{
+		"arial-bold",
+		"arial-narrow-bold",
+		"arial-narrow-regular",
+		"arial-regular",
+		"english-gothic-regular",
+		"univers-bold",
+		"univers-regular",
+	}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("Expected [%+v]\nActual [%+v]", expectedNames, names)
+	}
+
+	expectedFamilies := []string{"Arial", "Arial Narrow", "English Gothic", "Univers"}
+	if families := registry.Families(); !reflect.DeepEqual(families, expectedFamilies) {
+		t.Errorf("Expected [%+v]\nActual [%+v]", expectedFamilies, families)
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	registry := NewRegistry()
+	arial, err := registry.Register("Arial", draw2d.FontFamilySans, draw2d.FontStyleNormal, WeightRegular, ttf.Arial)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first font registered under a name keeps it
+	if _, err = registry.Register("Arial", draw2d.FontFamilySans, draw2d.FontStyleNormal, WeightRegular, ttf.ArialBold); !errors.Is(err, ErrFontRegistered) {
+		t.Errorf("Expected [%v]\nActual [%v]", ErrFontRegistered, err)
+	}
+	if f, _ := registry.Lookup(arial.FontData()); f.Font != arial.Font {
+		t.Errorf("Expected [%v] to keep its font", arial.Name)
+	}
+
+	// registries dont share their fonts, or wait on each other
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			other := NewRegistry()
+			if _, err := other.Register("Arial", draw2d.FontFamilySans, draw2d.FontStyleNormal, WeightRegular, ttf.Arial); err != nil {
+				t.Error(err)
+			}
+			registry.Lookup(arial.FontData())
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRegistryLookup(t *testing.T) {
+	registry, err := NewBundledRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		fontData draw2d.FontData
+		expected string
+		found    bool
+	}{
+		"Name": {
+			draw2d.FontData{Name: "arial-narrow-bold"},
+			"arial-narrow-bold",
+			true,
+		},
+		"Alias": {
+			draw2d.FontData{Name: "bold"},
+			"univers-bold",
+			true,
+		},
+		"Unknown Name": {
+			draw2d.FontData{Name: "missing"},
+			"univers-regular",
+			true,
+		},
+		"Unknown Name Serif": {
+			draw2d.FontData{Name: "luxi", Family: draw2d.FontFamilySerif},
+			"english-gothic-regular",
+			true,
+		},
+		"Unknown Name Bold": {
+			draw2d.FontData{Name: "luxi", Family: draw2d.FontFamilySans, Style: draw2d.FontStyleBold},
+			"univers-bold",
+			true,
+		},
+		"Sans": {
+			draw2d.FontData{Family: draw2d.FontFamilySans},
+			"univers-regular",
+			true,
+		},
+		"Sans Bold": {
+			draw2d.FontData{Family: draw2d.FontFamilySans, Style: draw2d.FontStyleBold},
+			"univers-bold",
+			true,
+		},
+		"Sans Bold Italic": {
+			draw2d.FontData{Family: draw2d.FontFamilySans, Style: draw2d.FontStyleBold | draw2d.FontStyleItalic},
+			"univers-bold",
+			true,
+		},
+		"Serif": {
+			draw2d.FontData{Family: draw2d.FontFamilySerif},
+			"english-gothic-regular",
+			true,
+		},
+		"Serif Bold": {
+			draw2d.FontData{Family: draw2d.FontFamilySerif, Style: draw2d.FontStyleBold},
+			"english-gothic-regular",
+			true,
+		},
+		"Mono Bold": {
+			draw2d.FontData{Family: draw2d.FontFamilyMono, Style: draw2d.FontStyleBold},
+			"univers-bold",
+			true,
+		},
+	}
+
+	for name, tt := range tests {
+		f, found := registry.Lookup(tt.fontData)
+		if found != tt.found || f.Name != tt.expected {
+			t.Errorf("%v: Expected [%+v %v]\nActual [%+v %v]", name, tt.expected, tt.found, f.Name, found)
+		}
+	}
+
+	f, found := registry.LookupFamily("arial narrow", WeightBold, draw2d.FontStyleNormal)
+	if !found || f.Name != "arial-narrow-bold" {
+		t.Errorf("Expected [arial-narrow-bold]\nActual [%+v %v]", f.Name, found)
+	}
+
+	loaded, err := registry.Load(f.FontData())
+	if err != nil || loaded != f.Font {
+		t.Errorf("Expected [%p]\nActual [%p %v]", f.Font, loaded, err)
+	}
+}
+
+func TestRegistryAttach(t *testing.T) {
+	registry := NewRegistry()
+	arial, err := registry.Register("Arial", draw2d.FontFamilySans, draw2d.FontStyleNormal, WeightRegular, ttf.Arial)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gc := draw2dimg.NewGraphicContext(image.NewRGBA(image.Rect(0, 0, 10, 10)))
+	registry.Attach(gc)
+
+	typeFace := TypeFace{
+		Name:      "arial",
+		Size:      34,
+		FontData:  arial.FontData(),
+		Face:      GetFace(gc, arial.FontData(), 34),
+		FontCache: registry,
+	}
+
+	resized, err := ResizeTypeFace(typeFace, 17)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := GetGlyphMetrics(typeFace, 'M').Advance / 2
+	actual := GetGlyphMetrics(resized, 'M').Advance
+	if math.Abs(expected-actual) > 0.1 {
+		t.Errorf("Expected advance [%v], Got [%v]", expected, actual)
+	}
+
+	// the bundled fonts arent in this registry, so it falls back to its own font
+	typeFace.FontData = draw2d.FontData{Name: "bold"}
+	fallback, err := ResizeTypeFace(typeFace, 17)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if advance := GetGlyphMetrics(fallback, 'M').Advance; advance != actual {
+		t.Errorf("Expected advance [%v], Got [%v]", actual, advance)
+	}
+
+	// nothing to fall back to
+	typeFace.FontCache = NewRegistry()
+	if _, err = ResizeTypeFace(typeFace, 17); err == nil {
+		t.Error("Expected an error for a font that isnt in the registry")
+	}
+}
@@ -24,6 +24,14 @@ type TypeFace struct {
 	Spacing               float64
 	Face                  font.Face
 	StrokeStyle           draw2d.StrokeStyle
+	DisableKerning        bool
+	// where the font is loaded from when the face is resized, the global font cache if nil,
+	// which only has the bundled fonts after UseBundledFonts
+	FontCache draw2d.FontCache
+	// what reads and draws the font, Face has to come from the same backend
+	Backend Backend
+	// the axes of a variable font, they need BackendOpenType and a Face made with them
+	Variations []Variation
 }
 
 type GlyphMetrics struct {
@@ -62,13 +70,22 @@ var (
 
 func GetTextWidth(tf TypeFace, text string) float64 {
 	var w float64
-	for i, char := range text {
-		w += GetGlyphWidth(tf, char)
+	var last rune
+	var n int
 
-		if i+1 == len(text) {
-			b := GetGlyphMetrics(tf, char)
-			w += b.BearingRight + b.BearingLeft
+	for _, char := range text {
+		if n > 0 {
+			w += GetKerning(tf, last, char)
 		}
+
+		w += GetGlyphWidth(tf, char)
+		last = char
+		n++
+	}
+
+	if n > 0 {
+		b := GetGlyphMetrics(tf, last)
+		w += b.BearingRight + b.BearingLeft
 	}
 
 	return math.Round(w*100) / 100
@@ -167,7 +184,7 @@ func GetGlyphBounds(tf TypeFace, char rune) GlyphBounds {
 // Metrics holds the metrics for a Face. A visual depiction is at
 // https://developer.apple.com/library/mac/documentation/TextFonts/Conceptual/CocoaTextArchitecture/Art/glyph_metrics_2x.png
 func GetFaceMetrics(tf TypeFace) FaceMetrics {
-	cacheKey := fmt.Sprintf("%s.%v", tf.Name, tf.Size)
+	cacheKey := fmt.Sprintf("%s%s.%v", tf.Name, variationsKey(tf.Variations), tf.Size)
 	if cacheKey != "" {
 		mutex.Lock()
 		cachedVersion, exists := cache_facemetrics[cacheKey]
@@ -217,21 +234,95 @@ func GetFace(gc *draw2dimg.GraphicContext, fontData draw2d.FontData, size float6
 		panic(err)
 	}
 
+	return newFace(font, fontSource(gc.FontCache, fontData), size)
+}
+
+// ResizeTypeFace returns a copy of tf at a different size, loading its font from tf.FontCache,
+// or the global font cache if it hasnt got one. The global font cache only has the bundled
+// fonts once UseBundledFonts has been called, so set tf.FontCache to load them without it.
+func ResizeTypeFace(tf TypeFace, size float64) (TypeFace, error) {
+	cache := tf.FontCache
+	if cache == nil {
+		cache = draw2d.GetGlobalFontCache()
+	}
+
+	if tf.Backend == BackendOpenType {
+		if _, err := loadOpenType(cache, tf.FontData); err != nil {
+			return tf, resizeError(tf, err)
+		}
+
+		face, err := GetVariableFace(cache, tf.FontData, size, tf.Variations)
+		if err != nil {
+			return tf, err
+		}
+
+		tf.Size = size
+		tf.Face = face
+
+		return tf, nil
+	}
+
+	if len(tf.Variations) > 0 {
+		return tf, fmt.Errorf("font %s: %w (variations need %v)", tf.FontData.Name, ErrFontNotSupported, BackendOpenType)
+	}
+
+	font, err := cache.Load(tf.FontData)
+	if err != nil {
+		return tf, resizeError(tf, err)
+	}
+
+	tf.Size = size
+	tf.Face = newFace(font, fontSource(cache, tf.FontData), size)
+
+	return tf, nil
+}
+
+// resizeError says where the font should come from when it couldnt be loaded from the
+// global font cache.
+func resizeError(tf TypeFace, err error) error {
+	if tf.FontCache != nil {
+		return err
+	}
+
+	return fmt.Errorf("font %s: %w (set the TypeFace's FontCache, or call UseBundledFonts to load it from the global font cache)", tf.FontData.Name, err)
+}
+
+// newFace returns a face for font, which also reads the GPOS kerning from src, the data the
+// font was parsed from, when it has it.
+//
+//nolint:ireturn,nolintlint
+func newFace(font *truetype.Font, src []byte, size float64) font.Face {
 	// Truetype stuff
 	opts := truetype.Options{
 		Size: size,
 	}
+	face := truetype.NewFace(font, &opts)
+
+	// freetype only knows about the kern table, pick up GPOS kerning if we have the source
+	if src == nil {
+		return face
+	}
+
+	kerned, err := WithKerning(face, src, size)
+	if err != nil {
+		return face
+	}
 
-	return truetype.NewFace(font, &opts)
+	return kerned
 }
 
+// SetFont gets gc ready to draw with typeFace. Fonts for BackendOpenType arent loaded into
+// gc, use DrawRune to draw them.
 func SetFont(gc *draw2dimg.GraphicContext, typeFace TypeFace) {
-	font, err := gc.FontCache.Load(typeFace.FontData)
-	if err != nil {
-		panic(err)
+	if typeFace.Backend != BackendOpenType {
+		font, err := gc.FontCache.Load(typeFace.FontData)
+		if err != nil {
+			panic(err)
+		}
+
+		gc.SetFont(font)
 	}
 
-	gc.SetFont(font)
 	gc.SetFontData(typeFace.FontData)
 	gc.SetFontSize(typeFace.Size)
 	gc.SetFillColor(typeFace.Color)
@@ -253,5 +344,5 @@ func glyphCacheKey(typeFace TypeFace, r rune) (string, bool) {
 		return "", false
 	}
 
-	return fmt.Sprintf("%s.%v.%s", typeFace.Name, typeFace.Size, string(r)), true
+	return fmt.Sprintf("%s%s.%v.%s", typeFace.Name, variationsKey(typeFace.Variations), typeFace.Size, string(r)), true
 }
@@ -2,7 +2,9 @@ package fonts
 
 import (
 	"image/color"
+	"math"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/golang/freetype/truetype"
@@ -30,6 +32,11 @@ func TestGetTextWidth(t *testing.T) {
 			float64(34),
 			[]float64{26.69, 14.23, 14.23, 21.35, 26.62, 23.33, 15.04, 12.27, 25.47, 18.9, 27.21, 23.33, 21.27, 24.32},
 		},
+		{
+			"Øster Allé",
+			float64(34),
+			[]float64{28.45, 21.35, 12.27, 21.25, 15.04, 18.9, 24.68, 14.23, 14.23, 21.25},
+		},
 	}
 
 	for _, tt := range tests {
@@ -75,9 +82,8 @@ func TestGetTextWidth(t *testing.T) {
 
 		actual := []float64{}
 
-		for i := 0; i < len(tt.label); i++ {
-			char := string(tt.label[i])
-			actual = append(actual, GetTextWidth(typeFace, char))
+		for _, r := range tt.label {
+			actual = append(actual, GetTextWidth(typeFace, string(r)))
 		}
 
 		if !reflect.DeepEqual(tt.expected, actual) {
@@ -85,3 +91,132 @@ func TestGetTextWidth(t *testing.T) {
 		}
 	}
 }
+
+func TestGetTextWidthMultiByte(t *testing.T) {
+	tests := map[string]struct {
+		label    string
+		fontSize float64
+		expected float64
+	}{
+		"Allé": {
+			"Allé",
+			float64(34),
+			64.7,
+		},
+		"Straße": {
+			"Straße",
+			float64(34),
+			108.16,
+		},
+		"Улица": {
+			"Улица",
+			float64(34),
+			104.12,
+		},
+	}
+
+	for name, tt := range tests {
+		f, err := truetype.Parse(ttf.ArialBold)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opts := truetype.Options{
+			Size: tt.fontSize,
+		}
+		face := truetype.NewFace(f, &opts)
+
+		typeFace := TypeFace{
+			Size: tt.fontSize,
+			FontData: draw2d.FontData{
+				Name:   "bold",
+				Family: draw2d.FontFamilySans,
+				Style:  draw2d.FontStyleNormal,
+			},
+			Face: face,
+		}
+
+		actual := GetTextWidth(typeFace, tt.label)
+
+		if tt.expected != actual {
+			t.Errorf("%v: Expected [%v], Got [%v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestResizeTypeFace(t *testing.T) {
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 34,
+	}
+
+	typeFace := TypeFace{
+		Size: 34,
+		FontData: draw2d.FontData{
+			Name:   "bold",
+			Family: draw2d.FontFamilySans,
+			Style:  draw2d.FontStyleNormal,
+		},
+		Face:    truetype.NewFace(f, &opts),
+		Spacing: 1,
+	}
+
+	resized, err := ResizeTypeFace(typeFace, 17)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resized.Size != 17 || resized.Spacing != 1 {
+		t.Errorf("Expected size [17] and spacing [1], Got [%v] and [%v]", resized.Size, resized.Spacing)
+	}
+
+	expected := GetGlyphMetrics(typeFace, 'M').Advance / 2
+	actual := GetGlyphMetrics(resized, 'M').Advance
+	if math.Abs(expected-actual) > 0.1 {
+		t.Errorf("Expected advance [%v], Got [%v]", expected, actual)
+	}
+
+	typeFace.FontCache = NewRegistry()
+	_, err = ResizeTypeFace(typeFace, 17)
+	if err == nil {
+		t.Error("Expected an error for a font that isnt in the cache")
+	}
+}
+
+func TestResizeTypeFaceFontCache(t *testing.T) {
+	// as if UseBundledFonts had never been called
+	global := draw2d.GetGlobalFontCache()
+	draw2d.SetFontCache(MyFontCache{})
+	defer draw2d.SetFontCache(global)
+
+	registry, err := NewBundledRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, backend := range []Backend{BackendTrueType, BackendOpenType} {
+		typeFace := TypeFace{
+			Name:     "resize-font-cache",
+			Size:     34,
+			FontData: draw2d.FontData{Name: "univers-bold"},
+			Backend:  backend,
+		}
+
+		if _, err = ResizeTypeFace(typeFace, 17); err == nil || !strings.Contains(err.Error(), "FontCache") {
+			t.Errorf("%v: Expected an error saying to set the FontCache, Got [%v]", backend, err)
+		}
+
+		typeFace.FontCache = registry
+		resized, err := ResizeTypeFace(typeFace, 17)
+		if err != nil {
+			t.Fatalf("%v: %v", backend, err)
+		}
+		if resized.Size != 17 || resized.Face == nil {
+			t.Errorf("%v: Expected a face at size [17], Got [%v]", backend, resized.Size)
+		}
+	}
+}
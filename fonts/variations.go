@@ -0,0 +1,1357 @@
+package fonts
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/llgcode/draw2d"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// Variation sets one of a variable font's axes, eg {"wght", 600} or {"wdth", 75}.
+type Variation struct {
+	Axis  string
+	Value float64
+}
+
+// Axis is one of the ways a variable font can vary.
+type Axis struct {
+	Tag     string
+	Min     float64
+	Default float64
+	Max     float64
+}
+
+// variableFont holds the tables needed to vary a truetype outlined font. Only glyf outlines
+// (and their advances) and the MVAR metrics are varied, cff2 outlines and kerning arent.
+// A RegisteredFont keeps the one parsed when it was registered.
+// https://learn.microsoft.com/en-us/typography/opentype/spec/otvaroverview
+type variableFont struct {
+	axes []Axis
+	// piecewise linear maps of normalised coordinates for each axis, from the avar table
+	segmentMaps [][][2]float64
+	upem        float64
+
+	glyf, loca  []byte
+	locaLong    bool
+	hmtx        []byte
+	numHMetrics int
+
+	gvar         []byte
+	glyphVars    []uint32
+	sharedTuples [][]float64
+	dataStart    int
+
+	// MVAR value records, the tag to its delta set
+	metrics map[string][2]int
+	store   itemVariationStore
+
+	// HVAR advance deltas, the delta set of each glyph when they arent in glyph order
+	hasHVAR      bool
+	advanceMap   [][2]int
+	advanceStore itemVariationStore
+}
+
+// Axes returns the axes of the variable font src, none if it isnt one.
+func Axes(src []byte) []Axis {
+	return parseAxes(fontTable(src, 0, "fvar"))
+}
+
+func parseAxes(fvar []byte) []Axis {
+	if len(fvar) < 16 {
+		return nil
+	}
+
+	axesOffset := int(binary.BigEndian.Uint16(fvar[4:]))
+	axisCount := int(binary.BigEndian.Uint16(fvar[8:]))
+	axisSize := int(binary.BigEndian.Uint16(fvar[10:]))
+
+	axes := []Axis{}
+	for i := 0; i < axisCount; i++ {
+		a := axesOffset + i*axisSize
+		if axisSize < 20 || a+20 > len(fvar) {
+			break
+		}
+		axes = append(axes, Axis{
+			Tag:     string(fvar[a : a+4]),
+			Min:     fixed16(fvar[a+4:]),
+			Default: fixed16(fvar[a+8:]),
+			Max:     fixed16(fvar[a+12:]),
+		})
+	}
+
+	return axes
+}
+
+// parseVariableFont reads the tables of the font at dir in src, nil if it isnt a variable
+// font with glyf outlines.
+func parseVariableFont(src []byte, dir int) *variableFont {
+	axes := parseAxes(fontTable(src, dir, "fvar"))
+	head := fontTable(src, dir, "head")
+	hhea := fontTable(src, dir, "hhea")
+	gvar := fontTable(src, dir, "gvar")
+	if len(axes) == 0 || len(head) < 54 || len(hhea) < 36 || len(gvar) < 20 {
+		return nil
+	}
+
+	vf := &variableFont{
+		axes:        axes,
+		upem:        float64(binary.BigEndian.Uint16(head[18:])),
+		glyf:        fontTable(src, dir, "glyf"),
+		loca:        fontTable(src, dir, "loca"),
+		locaLong:    binary.BigEndian.Uint16(head[50:]) != 0,
+		hmtx:        fontTable(src, dir, "hmtx"),
+		numHMetrics: int(binary.BigEndian.Uint16(hhea[34:])),
+		gvar:        gvar,
+	}
+	if vf.glyf == nil || vf.loca == nil || vf.upem == 0 {
+		return nil
+	}
+
+	// the gvar header, the offsets of each glyph's variations follow it
+	axisCount := int(binary.BigEndian.Uint16(gvar[4:]))
+	sharedCount := int(binary.BigEndian.Uint16(gvar[6:]))
+	sharedOffset := int(binary.BigEndian.Uint32(gvar[8:]))
+	glyphCount := int(binary.BigEndian.Uint16(gvar[12:]))
+	long := binary.BigEndian.Uint16(gvar[14:])&1 != 0
+	vf.dataStart = int(binary.BigEndian.Uint32(gvar[16:]))
+	if axisCount != len(axes) {
+		return nil
+	}
+
+	for i := 0; i <= glyphCount; i++ {
+		if long {
+			if 20+4*i+4 > len(gvar) {
+				return nil
+			}
+			vf.glyphVars = append(vf.glyphVars, binary.BigEndian.Uint32(gvar[20+4*i:]))
+		} else {
+			if 20+2*i+2 > len(gvar) {
+				return nil
+			}
+			vf.glyphVars = append(vf.glyphVars, 2*uint32(binary.BigEndian.Uint16(gvar[20+2*i:])))
+		}
+	}
+
+	for i := 0; i < sharedCount; i++ {
+		tuple, ok := readTuple(gvar, sharedOffset+i*2*axisCount, axisCount)
+		if !ok {
+			return nil
+		}
+		vf.sharedTuples = append(vf.sharedTuples, tuple)
+	}
+
+	vf.segmentMaps = parseAvar(fontTable(src, dir, "avar"), len(axes))
+	vf.metrics, vf.store = parseMVAR(fontTable(src, dir, "MVAR"))
+	vf.hasHVAR, vf.advanceMap, vf.advanceStore = parseHVAR(fontTable(src, dir, "HVAR"))
+
+	return vf
+}
+
+// normalise turns the variations into coordinates between -1 and 1 for each axis, axes that
+// arent in variations stay at their default and values are clamped to the axis.
+func (vf *variableFont) normalise(variations []Variation) []float64 {
+	coords := make([]float64, len(vf.axes))
+
+	for i, a := range vf.axes {
+		v := a.Default
+		for _, variation := range variations {
+			if variation.Axis == a.Tag {
+				v = math.Max(a.Min, math.Min(a.Max, variation.Value))
+			}
+		}
+
+		var n float64
+		switch {
+		case v < a.Default:
+			n = (v - a.Default) / (a.Default - a.Min)
+		case v > a.Default:
+			n = (v - a.Default) / (a.Max - a.Default)
+		}
+
+		if i < len(vf.segmentMaps) {
+			n = mapSegments(vf.segmentMaps[i], n)
+		}
+
+		// coordinates are stored as 2.14 numbers
+		coords[i] = math.Round(n*16384) / 16384
+	}
+
+	return coords
+}
+
+// mapSegments maps a normalised coordinate through an avar segment map.
+func mapSegments(segments [][2]float64, n float64) float64 {
+	for i := 1; i < len(segments); i++ {
+		from, to := segments[i-1], segments[i]
+		if n <= to[0] {
+			if to[0] == from[0] {
+				return to[1]
+			}
+			return from[1] + (n-from[0])*(to[1]-from[1])/(to[0]-from[0])
+		}
+	}
+
+	return n
+}
+
+func parseAvar(avar []byte, axisCount int) [][][2]float64 {
+	if len(avar) < 8 || int(binary.BigEndian.Uint16(avar[6:])) != axisCount {
+		return nil
+	}
+
+	maps := make([][][2]float64, axisCount)
+	o := 8
+	for i := range maps {
+		if o+2 > len(avar) {
+			return nil
+		}
+		count := int(binary.BigEndian.Uint16(avar[o:]))
+		o += 2
+		for j := 0; j < count; j++ {
+			if o+4 > len(avar) {
+				return nil
+			}
+			maps[i] = append(maps[i], [2]float64{f2dot14(avar[o:]), f2dot14(avar[o+2:])})
+			o += 4
+		}
+	}
+
+	return maps
+}
+
+// glyphPoint is a point of a glyph outline, in font units with y going up.
+type glyphPoint struct {
+	x, y    float64
+	onCurve bool
+}
+
+type glyphOutline struct {
+	points  []glyphPoint
+	ends    []int
+	advance float64
+}
+
+type glyphComponent struct {
+	glyph      int
+	dx, dy     float64
+	a, b, c, d float64
+}
+
+// outline returns the glyph varied to coords.
+func (vf *variableFont) outline(g int, coords []float64, depth int) (glyphOutline, error) {
+	if depth > 8 {
+		return glyphOutline{}, fmt.Errorf("%w (glyph %d is nested too deeply)", ErrFontNotSupported, g)
+	}
+
+	data, err := vf.glyphData(g)
+	if err != nil {
+		return glyphOutline{}, err
+	}
+
+	advance, lsb := vf.horizontalMetrics(g)
+
+	var o glyphOutline
+	var components []glyphComponent
+	var xMin float64
+
+	if len(data) >= 10 {
+		xMin = float64(int16(binary.BigEndian.Uint16(data[2:])))
+		if contours := int16(binary.BigEndian.Uint16(data)); contours >= 0 {
+			o, err = simpleGlyph(data, int(contours))
+		} else {
+			components, err = compositeGlyph(data)
+		}
+		if err != nil {
+			return glyphOutline{}, err
+		}
+	}
+
+	// the deltas are for the outline points, or the component offsets, then four phantom points
+	// for the origin, advance and vertical metrics
+	n := len(o.points)
+	if components != nil {
+		n = len(components)
+	}
+
+	original := make([]glyphPoint, n, n+4)
+	copy(original, o.points)
+	for i, c := range components {
+		original[i] = glyphPoint{x: c.dx, y: c.dy}
+	}
+	origin := xMin - lsb
+	original = append(original, glyphPoint{x: origin}, glyphPoint{x: origin + advance}, glyphPoint{}, glyphPoint{})
+
+	ends := o.ends
+	if components != nil {
+		// deltas arent inferred for composite glyphs
+		ends = nil
+	}
+	dx, dy := vf.deltas(g, coords, original, ends)
+
+	// keep the origin where it was
+	shift := dx[n]
+	o.advance = advance + dx[n+1] - dx[n]
+	if vf.hasHVAR {
+		o.advance = advance + vf.advanceDelta(g, coords)
+	}
+
+	for i := range o.points {
+		o.points[i].x += dx[i] - shift
+		o.points[i].y += dy[i]
+	}
+
+	for i, c := range components {
+		sub, err := vf.outline(c.glyph, coords, depth+1)
+		if err != nil {
+			return glyphOutline{}, err
+		}
+
+		offset := len(o.points)
+		for _, p := range sub.points {
+			o.points = append(o.points, glyphPoint{
+				x:       c.a*p.x + c.c*p.y + c.dx + dx[i] - shift,
+				y:       c.b*p.x + c.d*p.y + c.dy + dy[i],
+				onCurve: p.onCurve,
+			})
+		}
+		for _, e := range sub.ends {
+			o.ends = append(o.ends, e+offset)
+		}
+	}
+
+	return o, nil
+}
+
+func (vf *variableFont) glyphData(g int) ([]byte, error) {
+	var start, end int
+	if vf.locaLong {
+		if 4*g+8 > len(vf.loca) {
+			return nil, fmt.Errorf("%w (glyph %d)", ErrFontNotSupported, g)
+		}
+		start = int(binary.BigEndian.Uint32(vf.loca[4*g:]))
+		end = int(binary.BigEndian.Uint32(vf.loca[4*g+4:]))
+	} else {
+		if 2*g+4 > len(vf.loca) {
+			return nil, fmt.Errorf("%w (glyph %d)", ErrFontNotSupported, g)
+		}
+		start = 2 * int(binary.BigEndian.Uint16(vf.loca[2*g:]))
+		end = 2 * int(binary.BigEndian.Uint16(vf.loca[2*g+2:]))
+	}
+
+	if start > end || end > len(vf.glyf) {
+		return nil, fmt.Errorf("%w (glyph %d)", ErrFontNotSupported, g)
+	}
+
+	return vf.glyf[start:end], nil
+}
+
+// horizontalMetrics returns the advance and left side bearing of the glyph, in font units.
+func (vf *variableFont) horizontalMetrics(g int) (float64, float64) {
+	i := g
+	if i >= vf.numHMetrics {
+		i = vf.numHMetrics - 1
+	}
+	if i < 0 || 4*i+4 > len(vf.hmtx) {
+		return 0, 0
+	}
+	advance := float64(binary.BigEndian.Uint16(vf.hmtx[4*i:]))
+
+	// the glyphs past numHMetrics only have a side bearing
+	var lsb float64
+	if g < vf.numHMetrics {
+		lsb = float64(int16(binary.BigEndian.Uint16(vf.hmtx[4*g+2:])))
+	} else if o := 4*vf.numHMetrics + 2*(g-vf.numHMetrics); o+2 <= len(vf.hmtx) {
+		lsb = float64(int16(binary.BigEndian.Uint16(vf.hmtx[o:])))
+	}
+
+	return advance, lsb
+}
+
+// simpleGlyph reads the points of a glyph made of contours.
+func simpleGlyph(data []byte, contours int) (glyphOutline, error) {
+	errGlyph := fmt.Errorf("%w (simple glyph is too short)", ErrFontNotSupported)
+
+	o := 10
+	if o+2*contours+2 > len(data) {
+		return glyphOutline{}, errGlyph
+	}
+
+	ends := make([]int, contours)
+	for i := range ends {
+		ends[i] = int(binary.BigEndian.Uint16(data[o:]))
+		o += 2
+	}
+	numPoints := 0
+	if contours > 0 {
+		numPoints = ends[contours-1] + 1
+	}
+
+	// skip the instructions
+	o += 2 + int(binary.BigEndian.Uint16(data[o:]))
+
+	flags := make([]byte, 0, numPoints)
+	for len(flags) < numPoints {
+		if o >= len(data) {
+			return glyphOutline{}, errGlyph
+		}
+		f := data[o]
+		o++
+		flags = append(flags, f)
+
+		// repeated
+		if f&0x08 != 0 {
+			if o >= len(data) {
+				return glyphOutline{}, errGlyph
+			}
+			for r := data[o]; r > 0 && len(flags) < numPoints; r-- {
+				flags = append(flags, f)
+			}
+			o++
+		}
+	}
+
+	points := make([]glyphPoint, numPoints)
+
+	// the x then the y coordinates, each relative to the one before
+	for axis, short, same := 0, byte(0x02), byte(0x10); axis < 2; axis, short, same = axis+1, 0x04, 0x20 {
+		var v float64
+		for i, f := range flags {
+			switch {
+			case f&short != 0:
+				if o+1 > len(data) {
+					return glyphOutline{}, errGlyph
+				}
+				d := float64(data[o])
+				o++
+				if f&same == 0 {
+					d = -d
+				}
+				v += d
+			case f&same == 0:
+				if o+2 > len(data) {
+					return glyphOutline{}, errGlyph
+				}
+				v += float64(int16(binary.BigEndian.Uint16(data[o:])))
+				o += 2
+			}
+
+			if axis == 0 {
+				points[i].x = v
+				points[i].onCurve = f&0x01 != 0
+			} else {
+				points[i].y = v
+			}
+		}
+	}
+
+	return glyphOutline{points: points, ends: ends}, nil
+}
+
+// compositeGlyph reads the components of a glyph made of other glyphs. Components
+// positioned by matching points are put at the origin.
+func compositeGlyph(data []byte) ([]glyphComponent, error) {
+	const (
+		argsAreWords   = 0x0001
+		argsAreXY      = 0x0002
+		haveScale      = 0x0008
+		moreComponents = 0x0020
+		haveXYScale    = 0x0040
+		haveTwoByTwo   = 0x0080
+	)
+
+	components := []glyphComponent{}
+
+	o := 10
+	for {
+		if o+4 > len(data) {
+			return nil, fmt.Errorf("%w (composite glyph is too short)", ErrFontNotSupported)
+		}
+		flags := binary.BigEndian.Uint16(data[o:])
+		c := glyphComponent{glyph: int(binary.BigEndian.Uint16(data[o+2:])), a: 1, d: 1}
+		o += 4
+
+		var arg1, arg2 float64
+		if flags&argsAreWords != 0 {
+			if o+4 > len(data) {
+				return nil, fmt.Errorf("%w (composite glyph is too short)", ErrFontNotSupported)
+			}
+			arg1 = float64(int16(binary.BigEndian.Uint16(data[o:])))
+			arg2 = float64(int16(binary.BigEndian.Uint16(data[o+2:])))
+			o += 4
+		} else {
+			if o+2 > len(data) {
+				return nil, fmt.Errorf("%w (composite glyph is too short)", ErrFontNotSupported)
+			}
+			arg1 = float64(int8(data[o]))
+			arg2 = float64(int8(data[o+1]))
+			o += 2
+		}
+		if flags&argsAreXY != 0 {
+			c.dx, c.dy = arg1, arg2
+		}
+
+		var scales int
+		switch {
+		case flags&haveScale != 0:
+			scales = 1
+		case flags&haveXYScale != 0:
+			scales = 2
+		case flags&haveTwoByTwo != 0:
+			scales = 4
+		}
+		if o+2*scales > len(data) {
+			return nil, fmt.Errorf("%w (composite glyph is too short)", ErrFontNotSupported)
+		}
+		switch scales {
+		case 1:
+			c.a = f2dot14(data[o:])
+			c.d = c.a
+		case 2:
+			c.a, c.d = f2dot14(data[o:]), f2dot14(data[o+2:])
+		case 4:
+			c.a, c.b, c.c, c.d = f2dot14(data[o:]), f2dot14(data[o+2:]), f2dot14(data[o+4:]), f2dot14(data[o+6:])
+		}
+		o += 2 * scales
+
+		components = append(components, c)
+		if flags&moreComponents == 0 {
+			return components, nil
+		}
+	}
+}
+
+// deltas adds up the gvar deltas for each of the points at coords. Points a tuple doesnt
+// move are moved by interpolating the points around them on the contour, when there are contours.
+// https://learn.microsoft.com/en-us/typography/opentype/spec/gvar
+func (vf *variableFont) deltas(g int, coords []float64, points []glyphPoint, ends []int) ([]float64, []float64) {
+	dx := make([]float64, len(points))
+	dy := make([]float64, len(points))
+
+	if g+1 >= len(vf.glyphVars) {
+		return dx, dy
+	}
+	start := vf.dataStart + int(vf.glyphVars[g])
+	end := vf.dataStart + int(vf.glyphVars[g+1])
+	if start >= end || end > len(vf.gvar) {
+		return dx, dy
+	}
+	data := vf.gvar[start:end]
+	if len(data) < 4 {
+		return dx, dy
+	}
+
+	const (
+		sharedPointNumbers  = 0x8000
+		embeddedPeak        = 0x8000
+		intermediateRegion  = 0x4000
+		privatePointNumbers = 0x2000
+	)
+
+	count := binary.BigEndian.Uint16(data)
+	serialised := int(binary.BigEndian.Uint16(data[2:]))
+	axisCount := len(vf.axes)
+
+	var shared []int
+	if count&sharedPointNumbers != 0 {
+		var ok bool
+		if shared, serialised, ok = readPoints(data, serialised); !ok {
+			return dx, dy
+		}
+	}
+
+	header := 4
+	for t := 0; t < int(count&0x0FFF); t++ {
+		if header+4 > len(data) {
+			break
+		}
+		size := int(binary.BigEndian.Uint16(data[header:]))
+		index := binary.BigEndian.Uint16(data[header+2:])
+		header += 4
+
+		var peak, startTuple, endTuple []float64
+		var ok bool
+		if index&embeddedPeak != 0 {
+			if peak, ok = readTuple(data, header, axisCount); !ok {
+				break
+			}
+			header += 2 * axisCount
+		} else if i := int(index & 0x0FFF); i < len(vf.sharedTuples) {
+			peak = vf.sharedTuples[i]
+		} else {
+			break
+		}
+		if index&intermediateRegion != 0 {
+			startTuple, ok = readTuple(data, header, axisCount)
+			if !ok {
+				break
+			}
+			endTuple, ok = readTuple(data, header+2*axisCount, axisCount)
+			if !ok {
+				break
+			}
+			header += 4 * axisCount
+		}
+
+		tupleData := serialised
+		serialised += size
+		if serialised > len(data) {
+			break
+		}
+
+		scalar := tupleScalar(coords, peak, startTuple, endTuple)
+		if scalar == 0 {
+			continue
+		}
+
+		touched := shared
+		o := tupleData
+		if index&privatePointNumbers != 0 {
+			if touched, o, ok = readPoints(data[:serialised], o); !ok {
+				break
+			}
+		}
+
+		n := len(touched)
+		if touched == nil {
+			n = len(points)
+		}
+		xs, o, ok := readDeltas(data[:serialised], o, n)
+		if !ok {
+			break
+		}
+		ys, _, ok := readDeltas(data[:serialised], o, n)
+		if !ok {
+			break
+		}
+
+		tx := make([]float64, len(points))
+		ty := make([]float64, len(points))
+		if touched == nil {
+			copy(tx, xs)
+			copy(ty, ys)
+		} else {
+			moved := make([]bool, len(points))
+			for i, p := range touched {
+				if p < len(points) {
+					tx[p], ty[p] = xs[i], ys[i]
+					moved[p] = true
+				}
+			}
+			interpolateUntouched(points, ends, moved, tx, ty)
+		}
+
+		for i := range points {
+			dx[i] += scalar * tx[i]
+			dy[i] += scalar * ty[i]
+		}
+	}
+
+	return dx, dy
+}
+
+// tupleScalar is how much of a tuple's deltas apply at coords.
+func tupleScalar(coords, peak, start, end []float64) float64 {
+	scalar := 1.0
+
+	for i, p := range peak {
+		if p == 0 || i >= len(coords) {
+			continue
+		}
+		c := coords[i]
+
+		lo, hi := math.Min(0, p), math.Max(0, p)
+		if start != nil && end != nil {
+			lo, hi = start[i], end[i]
+			// regions like these dont make sense so they are ignored
+			if lo > p || p > hi || (lo < 0 && hi > 0) {
+				continue
+			}
+		}
+
+		switch {
+		case c == p:
+		case c <= lo || c >= hi:
+			return 0
+		case c < p:
+			scalar *= (c - lo) / (p - lo)
+		default:
+			scalar *= (hi - c) / (hi - p)
+		}
+	}
+
+	return scalar
+}
+
+// interpolateUntouched moves the points no deltas were given for, in proportion to the
+// touched points either side of them on their contour.
+func interpolateUntouched(points []glyphPoint, ends []int, moved []bool, dx, dy []float64) {
+	start := 0
+	for _, end := range ends {
+		if end >= len(points) {
+			break
+		}
+
+		touched := []int{}
+		for i := start; i <= end; i++ {
+			if moved[i] {
+				touched = append(touched, i)
+			}
+		}
+
+		if len(touched) > 0 {
+			for k, t := range touched {
+				next := touched[(k+1)%len(touched)]
+
+				// the untouched points after t up to the next touched one, round the contour
+				for i := t + 1; ; i++ {
+					if i > end {
+						i = start
+					}
+					if i == next {
+						break
+					}
+					dx[i] = interpolate(points[i].x, points[t].x, points[next].x, dx[t], dx[next])
+					dy[i] = interpolate(points[i].y, points[t].y, points[next].y, dy[t], dy[next])
+				}
+			}
+		}
+
+		start = end + 1
+	}
+}
+
+func interpolate(v, v1, v2, d1, d2 float64) float64 {
+	if v1 == v2 {
+		if d1 == d2 {
+			return d1
+		}
+		return 0
+	}
+
+	if v1 > v2 {
+		v1, v2 = v2, v1
+		d1, d2 = d2, d1
+	}
+
+	switch {
+	case v <= v1:
+		return d1
+	case v >= v2:
+		return d2
+	}
+
+	return d1 + (v-v1)*(d2-d1)/(v2-v1)
+}
+
+// readPoints reads packed point numbers at o, nil means every point.
+func readPoints(data []byte, o int) ([]int, int, bool) {
+	if o >= len(data) {
+		return nil, o, false
+	}
+
+	count := int(data[o])
+	o++
+	if count == 0 {
+		return nil, o, true
+	}
+	if count&0x80 != 0 {
+		if o >= len(data) {
+			return nil, o, false
+		}
+		count = (count&0x7F)<<8 | int(data[o])
+		o++
+	}
+
+	points := make([]int, 0, count)
+	p := 0
+	for len(points) < count {
+		if o >= len(data) {
+			return nil, o, false
+		}
+		control := data[o]
+		o++
+
+		run := int(control&0x7F) + 1
+		for i := 0; i < run && len(points) < count; i++ {
+			if control&0x80 != 0 {
+				if o+2 > len(data) {
+					return nil, o, false
+				}
+				p += int(binary.BigEndian.Uint16(data[o:]))
+				o += 2
+			} else {
+				if o >= len(data) {
+					return nil, o, false
+				}
+				p += int(data[o])
+				o++
+			}
+			points = append(points, p)
+		}
+	}
+
+	return points, o, true
+}
+
+// readDeltas reads n packed deltas at o.
+func readDeltas(data []byte, o, n int) ([]float64, int, bool) {
+	deltas := make([]float64, 0, n)
+
+	for len(deltas) < n {
+		if o >= len(data) {
+			return nil, o, false
+		}
+		control := data[o]
+		o++
+
+		run := int(control&0x3F) + 1
+		for i := 0; i < run && len(deltas) < n; i++ {
+			switch {
+			case control&0x80 != 0:
+				deltas = append(deltas, 0)
+			case control&0x40 != 0:
+				if o+2 > len(data) {
+					return nil, o, false
+				}
+				deltas = append(deltas, float64(int16(binary.BigEndian.Uint16(data[o:]))))
+				o += 2
+			default:
+				if o >= len(data) {
+					return nil, o, false
+				}
+				deltas = append(deltas, float64(int8(data[o])))
+				o++
+			}
+		}
+	}
+
+	return deltas, o, true
+}
+
+func readTuple(data []byte, o, axisCount int) ([]float64, bool) {
+	if o < 0 || o+2*axisCount > len(data) {
+		return nil, false
+	}
+
+	tuple := make([]float64, axisCount)
+	for i := range tuple {
+		tuple[i] = f2dot14(data[o+2*i:])
+	}
+
+	return tuple, true
+}
+
+// itemVariationStore holds the deltas of the MVAR and HVAR tables.
+// https://learn.microsoft.com/en-us/typography/opentype/spec/otvarcommonformats
+type itemVariationStore struct {
+	// the start, peak and end of each axis in each region
+	regions [][3][]float64
+	data    []itemVariationData
+}
+
+type itemVariationData struct {
+	regions []int
+	// the deltas of each item for each region
+	deltas [][]float64
+}
+
+func parseMVAR(mvar []byte) (map[string][2]int, itemVariationStore) {
+	if len(mvar) < 12 {
+		return nil, itemVariationStore{}
+	}
+
+	recordSize := int(binary.BigEndian.Uint16(mvar[6:]))
+	recordCount := int(binary.BigEndian.Uint16(mvar[8:]))
+	storeOffset := int(binary.BigEndian.Uint16(mvar[10:]))
+	if recordSize < 8 || storeOffset == 0 {
+		return nil, itemVariationStore{}
+	}
+
+	records := make(map[string][2]int)
+	for i := 0; i < recordCount; i++ {
+		r := 12 + i*recordSize
+		if r+8 > len(mvar) {
+			break
+		}
+		records[string(mvar[r:r+4])] = [2]int{
+			int(binary.BigEndian.Uint16(mvar[r+4:])),
+			int(binary.BigEndian.Uint16(mvar[r+6:])),
+		}
+	}
+
+	return records, parseItemVariationStore(mvar, storeOffset)
+}
+
+func parseItemVariationStore(b []byte, o int) itemVariationStore {
+	var store itemVariationStore
+	if o+8 > len(b) {
+		return store
+	}
+
+	regionList := o + int(binary.BigEndian.Uint32(b[o+2:]))
+	dataCount := int(binary.BigEndian.Uint16(b[o+6:]))
+
+	if regionList+4 <= len(b) {
+		axisCount := int(binary.BigEndian.Uint16(b[regionList:]))
+		regionCount := int(binary.BigEndian.Uint16(b[regionList+2:]))
+		for r := 0; r < regionCount; r++ {
+			var region [3][]float64
+			for k := range region {
+				region[k] = make([]float64, axisCount)
+			}
+			for a := 0; a < axisCount; a++ {
+				p := regionList + 4 + (r*axisCount+a)*6
+				if p+6 > len(b) {
+					return store
+				}
+				region[0][a], region[1][a], region[2][a] = f2dot14(b[p:]), f2dot14(b[p+2:]), f2dot14(b[p+4:])
+			}
+			store.regions = append(store.regions, region)
+		}
+	}
+
+	for i := 0; i < dataCount; i++ {
+		if o+8+4*i+4 > len(b) {
+			break
+		}
+		d := o + int(binary.BigEndian.Uint32(b[o+8+4*i:]))
+		if d+6 > len(b) {
+			break
+		}
+
+		itemCount := int(binary.BigEndian.Uint16(b[d:]))
+		wordCount := int(binary.BigEndian.Uint16(b[d+2:]))
+		regionCount := int(binary.BigEndian.Uint16(b[d+4:]))
+		longWords := wordCount&0x8000 != 0
+		wordCount &= 0x7FFF
+
+		ivd := itemVariationData{}
+		p := d + 6
+		for r := 0; r < regionCount && p+2 <= len(b); r++ {
+			ivd.regions = append(ivd.regions, int(binary.BigEndian.Uint16(b[p:])))
+			p += 2
+		}
+
+		for item := 0; item < itemCount; item++ {
+			row := make([]float64, regionCount)
+			for r := range row {
+				wide := r < wordCount
+				switch {
+				case longWords && wide && p+4 <= len(b):
+					row[r] = float64(int32(binary.BigEndian.Uint32(b[p:])))
+					p += 4
+				case (longWords || wide) && p+2 <= len(b):
+					row[r] = float64(int16(binary.BigEndian.Uint16(b[p:])))
+					p += 2
+				case !longWords && !wide && p+1 <= len(b):
+					row[r] = float64(int8(b[p]))
+					p++
+				}
+			}
+			ivd.deltas = append(ivd.deltas, row)
+		}
+
+		store.data = append(store.data, ivd)
+	}
+
+	return store
+}
+
+// delta is how much the item in the delta set moves at coords.
+func (store itemVariationStore) delta(outer, inner int, coords []float64) float64 {
+	if outer >= len(store.data) {
+		return 0
+	}
+
+	ivd := store.data[outer]
+	if inner >= len(ivd.deltas) {
+		return 0
+	}
+
+	var delta float64
+	for r, d := range ivd.deltas[inner] {
+		if r >= len(ivd.regions) || ivd.regions[r] >= len(store.regions) {
+			continue
+		}
+		region := store.regions[ivd.regions[r]]
+		delta += d * tupleScalar(coords, region[1], region[0], region[2])
+	}
+
+	return delta
+}
+
+// metricDelta is how much the MVAR table moves the metric with the given tag at coords, in font units.
+func (vf *variableFont) metricDelta(tag string, coords []float64) float64 {
+	index, ok := vf.metrics[tag]
+	if !ok {
+		return 0
+	}
+
+	return vf.store.delta(index[0], index[1], coords)
+}
+
+// parseHVAR reads the advance deltas of the HVAR table, the side bearings are left to gvar.
+// https://learn.microsoft.com/en-us/typography/opentype/spec/hvar
+func parseHVAR(hvar []byte) (bool, [][2]int, itemVariationStore) {
+	if len(hvar) < 20 {
+		return false, nil, itemVariationStore{}
+	}
+
+	storeOffset := int(binary.BigEndian.Uint32(hvar[4:]))
+	mapOffset := int(binary.BigEndian.Uint32(hvar[8:]))
+	if storeOffset == 0 {
+		return false, nil, itemVariationStore{}
+	}
+
+	return true, parseDeltaSetIndexMap(hvar, mapOffset), parseItemVariationStore(hvar, storeOffset)
+}
+
+// parseDeltaSetIndexMap reads the delta set of each item at o, nil when there isnt a map.
+func parseDeltaSetIndexMap(b []byte, o int) [][2]int {
+	if o == 0 || o+4 > len(b) {
+		return nil
+	}
+
+	entryFormat := int(b[o+1])
+	count := int(binary.BigEndian.Uint16(b[o+2:]))
+	p := o + 4
+	if b[o] == 1 {
+		if o+6 > len(b) {
+			return nil
+		}
+		count = int(binary.BigEndian.Uint32(b[o+2:]))
+		p = o + 6
+	}
+
+	innerBits := entryFormat&0x0f + 1
+	size := (entryFormat&0x30)>>4 + 1
+
+	var m [][2]int
+	for i := 0; i < count && p+size <= len(b); i++ {
+		var entry int
+		for _, c := range b[p : p+size] {
+			entry = entry<<8 | int(c)
+		}
+		p += size
+
+		m = append(m, [2]int{entry >> innerBits, entry & (1<<innerBits - 1)})
+	}
+
+	return m
+}
+
+// advanceDelta is how much the HVAR table moves the advance of glyph g at coords, in font units.
+func (vf *variableFont) advanceDelta(g int, coords []float64) float64 {
+	outer, inner := 0, g
+	if len(vf.advanceMap) > 0 {
+		// the glyphs past the end of the map use its last entry
+		i := g
+		if i >= len(vf.advanceMap) {
+			i = len(vf.advanceMap) - 1
+		}
+		outer, inner = vf.advanceMap[i][0], vf.advanceMap[i][1]
+	}
+
+	return vf.advanceStore.delta(outer, inner, coords)
+}
+
+// variableFace is a face for a variable font at a set of variations.
+type variableFace struct {
+	// the face of the default instance, for kerning and anything that cant be varied
+	font.Face
+	otf    *sfnt.Font
+	vf     *variableFont
+	coords []float64
+	// font units to pixels
+	scale float64
+
+	// the glyphs already varied to coords, they are needed for each advance and bounds
+	mu       sync.Mutex
+	outlines map[sfnt.GlyphIndex]glyphOutline
+}
+
+// NewVariableFace returns a face for the registered font at the given size with the axes
+// set by variations. Fonts that arent variable get a plain opentype face when there are no
+// variations, and an error when there are.
+//
+// Only truetype (glyf) outlines can be varied, fonts with cff2 outlines get an error. Kerning
+// always comes from the default instance, and the components of composite glyphs that are
+// positioned by matching points, rather than by offsets, are put at the glyph's origin.
+//
+//nolint:ireturn,nolintlint
+func NewVariableFace(f RegisteredFont, size float64, variations []Variation) (font.Face, error) {
+	if f.OpenType == nil {
+		return nil, fmt.Errorf("font %s: %w by x/image, use BackendTrueType", f.Name, ErrFontNotSupported)
+	}
+
+	face, err := NewOpenTypeFace(f.OpenType, size)
+	if err != nil || len(variations) == 0 {
+		return face, err
+	}
+
+	vf := f.variable
+	if vf == nil {
+		return nil, fmt.Errorf("font %s: %w (not a variable font with truetype outlines)", f.Name, ErrFontNotSupported)
+	}
+
+	return &variableFace{
+		Face:     face,
+		otf:      f.OpenType,
+		vf:       vf,
+		coords:   vf.normalise(variations),
+		scale:    size / vf.upem,
+		outlines: make(map[sfnt.GlyphIndex]glyphOutline),
+	}, nil
+}
+
+// GetVariableFace loads fontData from the cache and returns a face for it for BackendOpenType,
+// varied by variations. Variations need the cache to be a Registry.
+//
+//nolint:ireturn,nolintlint
+func GetVariableFace(cache draw2d.FontCache, fontData draw2d.FontData, size float64, variations []Variation) (font.Face, error) {
+	if len(variations) == 0 {
+		return GetOpenTypeFace(cache, fontData, size)
+	}
+
+	f, err := loadRegistered(cache, fontData)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewVariableFace(f, size, variations)
+}
+
+// loadRegistered returns the registered font for fontData, the cache has to be a Registry.
+func loadRegistered(cache draw2d.FontCache, fontData draw2d.FontData) (RegisteredFont, error) {
+	r, ok := cache.(*Registry)
+	if !ok {
+		return RegisteredFont{}, fmt.Errorf("font %s: the font cache cant load variable fonts, use a Registry", fontData.Name)
+	}
+
+	f, ok := r.Lookup(fontData)
+	if !ok {
+		return RegisteredFont{}, fmt.Errorf("font %s is not registered", fontData.Name)
+	}
+
+	return f, nil
+}
+
+func (f *variableFace) outline(r rune) (glyphOutline, bool) {
+	// a buffer per call, so faces can be shared between goroutines
+	var buf sfnt.Buffer
+	x, err := f.otf.GlyphIndex(&buf, r)
+	if err != nil {
+		return glyphOutline{}, false
+	}
+
+	f.mu.Lock()
+	o, ok := f.outlines[x]
+	f.mu.Unlock()
+	if ok {
+		return o, true
+	}
+
+	o, err = f.vf.outline(int(x), f.coords, 0)
+	if err != nil {
+		return glyphOutline{}, false
+	}
+
+	f.mu.Lock()
+	f.outlines[x] = o
+	f.mu.Unlock()
+
+	return o, true
+}
+
+func (f *variableFace) Metrics() font.Metrics {
+	m := f.Face.Metrics()
+
+	delta := func(tag string) fixed.Int26_6 {
+		return fixed.Int26_6(math.Round(f.vf.metricDelta(tag, f.coords) * f.scale * 64))
+	}
+	ascent, descent, lineGap := delta("hasc"), delta("hdsc"), delta("hlgp")
+
+	m.Ascent += ascent
+	m.Descent -= descent
+	m.Height += ascent - descent + lineGap
+	m.XHeight += delta("xhgt")
+	m.CapHeight += delta("cpht")
+
+	return m
+}
+
+func (f *variableFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	o, ok := f.outline(r)
+	if !ok {
+		return 0, false
+	}
+
+	return fixed.Int26_6(math.Round(o.advance * f.scale * 64)), true
+}
+
+func (f *variableFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	o, ok := f.outline(r)
+	if !ok {
+		return fixed.Rectangle26_6{}, 0, false
+	}
+
+	return o.bounds(f.scale), fixed.Int26_6(math.Round(o.advance * f.scale * 64)), true
+}
+
+func (f *variableFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	o, ok := f.outline(r)
+	if !ok {
+		return image.Rectangle{}, nil, image.Point{}, 0, false
+	}
+	advance := fixed.Int26_6(math.Round(o.advance * f.scale * 64))
+
+	b := o.bounds(f.scale).Add(dot)
+	dr := image.Rect(b.Min.X.Floor(), b.Min.Y.Floor(), b.Max.X.Ceil(), b.Max.Y.Ceil())
+	if dr.Empty() {
+		return image.Rectangle{}, image.NewAlpha(image.Rectangle{}), image.Point{}, advance, true
+	}
+
+	// the glyph origin inside the mask
+	ox := float32(unfix(dot.X)) - float32(dr.Min.X)
+	oy := float32(unfix(dot.Y)) - float32(dr.Min.Y)
+
+	z := vector.NewRasterizer(dr.Dx(), dr.Dy())
+	o.walk(f.scale,
+		func(x, y float64) { z.MoveTo(ox+float32(x), oy+float32(y)) },
+		func(x, y float64) { z.LineTo(ox+float32(x), oy+float32(y)) },
+		func(cx, cy, x, y float64) { z.QuadTo(ox+float32(cx), oy+float32(cy), ox+float32(x), oy+float32(y)) },
+		z.ClosePath,
+	)
+
+	mask := image.NewAlpha(image.Rect(0, 0, dr.Dx(), dr.Dy()))
+	z.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+
+	return dr, mask, image.Point{}, advance, true
+}
+
+// bounds returns the bounding box of the outline's points in pixels, with y going down.
+func (o glyphOutline) bounds(scale float64) fixed.Rectangle26_6 {
+	if len(o.points) == 0 {
+		return fixed.Rectangle26_6{}
+	}
+
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range o.points {
+		minX, maxX = math.Min(minX, p.x), math.Max(maxX, p.x)
+		minY, maxY = math.Min(minY, p.y), math.Max(maxY, p.y)
+	}
+
+	fix := func(v float64) fixed.Int26_6 {
+		return fixed.Int26_6(math.Round(v * scale * 64))
+	}
+
+	return fixed.Rectangle26_6{
+		Min: fixed.Point26_6{X: fix(minX), Y: -fix(maxY)},
+		Max: fixed.Point26_6{X: fix(maxX), Y: -fix(minY)},
+	}
+}
+
+// walk traces the contours of the outline in pixels with y going down, adding the on
+// curve points truetype leaves out between two off curve ones.
+func (o glyphOutline) walk(scale float64, moveTo, lineTo func(x, y float64), quadTo func(cx, cy, x, y float64), closePath func()) {
+	pt := func(p glyphPoint) (float64, float64) {
+		return p.x * scale, -p.y * scale
+	}
+
+	start := 0
+	for _, end := range o.ends {
+		if end >= len(o.points) || end < start {
+			break
+		}
+		contour := o.points[start : end+1]
+		start = end + 1
+		n := len(contour)
+
+		// start on the curve, halfway between the last and first points if they are both off it
+		first := -1
+		for i, p := range contour {
+			if p.onCurve {
+				first = i
+				break
+			}
+		}
+
+		var sx, sy float64
+		var from, count int
+		if first < 0 {
+			x0, y0 := pt(contour[n-1])
+			x1, y1 := pt(contour[0])
+			sx, sy = (x0+x1)/2, (y0+y1)/2
+			from, count = 0, n
+		} else {
+			sx, sy = pt(contour[first])
+			from, count = first+1, n-1
+		}
+		moveTo(sx, sy)
+
+		var cx, cy float64
+		pending := false
+		for k := 0; k < count; k++ {
+			p := contour[(from+k)%n]
+			x, y := pt(p)
+
+			if p.onCurve {
+				if pending {
+					quadTo(cx, cy, x, y)
+					pending = false
+				} else {
+					lineTo(x, y)
+				}
+				continue
+			}
+
+			if pending {
+				quadTo(cx, cy, (cx+x)/2, (cy+y)/2)
+			}
+			cx, cy = x, y
+			pending = true
+		}
+
+		if pending {
+			quadTo(cx, cy, sx, sy)
+		} else {
+			lineTo(sx, sy)
+		}
+		closePath()
+	}
+}
+
+// variationsKey returns the variations in a form that can be used in cache keys, sorted by axis.
+func variationsKey(variations []Variation) string {
+	if len(variations) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(variations))
+	for _, v := range variations {
+		parts = append(parts, fmt.Sprintf("%s=%v", v.Axis, v.Value))
+	}
+	sort.Strings(parts)
+
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// fixed16 reads a 16.16 fixed point number.
+func fixed16(b []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(b))) / 65536
+}
+
+// f2dot14 reads a 2.14 fixed point number.
+func f2dot14(b []byte) float64 {
+	return float64(int16(binary.BigEndian.Uint16(b))) / 16384
+}
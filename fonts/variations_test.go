@@ -0,0 +1,465 @@
+package fonts
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestAxes(t *testing.T) {
+	expected := []Axis{{Tag: "wght", Min: 100, Default: 400, Max: 900}}
+	if actual := Axes(variableUnivers(t)); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected [%+v]\nActual [%+v]", expected, actual)
+	}
+
+	if actual := Axes(ttf.Univers); len(actual) != 0 {
+		t.Errorf("Expected no axes\nActual [%+v]", actual)
+	}
+}
+
+func TestVariableFace(t *testing.T) {
+	registry := NewRegistry()
+	univers, err := registry.RegisterSource(variableUnivers(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plain, err := NewOpenTypeFace(univers.OpenType, 34)
+	if err != nil {
+		t.Fatal(err)
+	}
+	face := func(weight float64) font.Face {
+		f, err := NewVariableFace(univers, 34, []Variation{{"wght", weight}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	// at the default the outlines should be the same as the ones x/image reads, composites too
+	regular := face(400)
+	for _, r := range "Mellor Street Øster Allé 0123" {
+		expectedBounds, expectedAdvance, _ := plain.GlyphBounds(r)
+		actualBounds, actualAdvance, ok := regular.GlyphBounds(r)
+		if !ok || !closeFixed(expectedBounds.Min.X, actualBounds.Min.X) || !closeFixed(expectedBounds.Min.Y, actualBounds.Min.Y) ||
+			!closeFixed(expectedBounds.Max.X, actualBounds.Max.X) || !closeFixed(expectedBounds.Max.Y, actualBounds.Max.Y) ||
+			!closeFixed(expectedAdvance, actualAdvance) {
+			t.Errorf("%q: Expected [%v %v]\nActual [%v %v]", r, expectedBounds, expectedAdvance, actualBounds, actualAdvance)
+		}
+	}
+
+	// the M gets a quarter wider at the heaviest, and half that half way there
+	scale := 34 / float64(univers.OpenType.UnitsPerEm())
+	bounds, advance, _ := plain.GlyphBounds('M')
+	width := unfix(bounds.Max.X - bounds.Min.X)
+
+	tests := map[string]struct {
+		weight  float64
+		advance float64
+		width   float64
+	}{
+		"Default": {400, unfix(advance), width},
+		"Bold":    {650, unfix(advance) * 1.125, width * 1.125},
+		"Black":   {900, unfix(advance) * 1.25, width * 1.25},
+		"Clamped": {1000, unfix(advance) * 1.25, width * 1.25},
+	}
+
+	for name, tt := range tests {
+		b, a, _ := face(tt.weight).GlyphBounds('M')
+		// the deltas are rounded to whole units, at each side of the glyph
+		if math.Abs(unfix(a)-tt.advance) > 2*scale || math.Abs(unfix(b.Max.X-b.Min.X)-tt.width) > 2*scale {
+			t.Errorf("%v: Expected [%v %v]\nActual [%v %v]", name, tt.advance, tt.width, unfix(a), unfix(b.Max.X-b.Min.X))
+		}
+	}
+
+	// MVAR moves the ascent up 100 units at the heaviest
+	black := TypeFace{Name: "variable", Size: 34, Face: face(900), Backend: BackendOpenType, Variations: []Variation{{"wght", 900}}}
+	expected := GetFaceMetrics(TypeFace{Name: "variable", Size: 34, Face: plain}).Ascent + 100*scale
+	if actual := GetFaceMetrics(black).Ascent; math.Abs(expected-actual) > 0.02 {
+		t.Errorf("Expected ascent [%v]\nActual [%v]", expected, actual)
+	}
+
+	// the mask covers the glyph drawn at the dot
+	dot := fixed.P(10, 50)
+	dr, mask, _, _, ok := black.Face.Glyph(dot, 'M')
+	b, _, _ := black.Face.GlyphBounds('M')
+	if !ok || dr != image.Rect((b.Min.X+dot.X).Floor(), (b.Min.Y+dot.Y).Floor(), (b.Max.X+dot.X).Ceil(), (b.Max.Y+dot.Y).Ceil()) {
+		t.Errorf("Expected the mask to cover [%v]\nActual [%v]", b, dr)
+	} else if _, _, _, alpha := mask.At(dr.Dx()/2, dr.Dy()-2).RGBA(); alpha == 0 {
+		t.Error("Expected the bottom middle of the M to be drawn")
+	}
+
+	// each glyph is only varied once by a face
+	bold, ok := face(650).(*variableFace)
+	if !ok {
+		t.Fatalf("Expected a variable face\nActual [%T]", face(650))
+	}
+	for _, r := range "MMM" {
+		bold.GlyphAdvance(r)
+		bold.GlyphBounds(r)
+	}
+	if len(bold.outlines) != 1 {
+		t.Errorf("Expected [1] outline\nActual [%v]", len(bold.outlines))
+	}
+
+	// the variations are kept by the registry
+	if _, err = GetVariableFace(registry, univers.FontData(), 34, []Variation{{"wght", 900}}); err != nil {
+		t.Error(err)
+	}
+	if _, err = GetVariableFace(MyFontCache{}, univers.FontData(), 34, []Variation{{"wght", 900}}); err == nil {
+		t.Error("Expected an error for a font cache that isnt a registry")
+	}
+
+	if _, err = NewVariableFace(univers, 34, nil); err != nil {
+		t.Error(err)
+	}
+	universBold, _ := registry.Register("Univers", draw2d.FontFamilySans, draw2d.FontStyleBold, WeightBold, ttf.UniversBold)
+	if _, err = NewVariableFace(universBold, 34, []Variation{{"wght", 900}}); !errors.Is(err, ErrFontNotSupported) {
+		t.Errorf("Expected [%v]\nActual [%v]", ErrFontNotSupported, err)
+	}
+}
+
+func TestVariableFaceSelawik(t *testing.T) {
+	src, err := os.ReadFile("testdata/Selawik-VF-Subset.ttf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedAxes := []Axis{{Tag: "wght", Min: 300, Default: 400, Max: 700}}
+	if actual := Axes(src); !reflect.DeepEqual(actual, expectedAxes) {
+		t.Errorf("Expected [%+v]\nActual [%+v]", expectedAxes, actual)
+	}
+
+	// its tables arent on four byte boundaries, which x/image wont read
+	selawik, err := NewRegistry().RegisterSource(withTables(t, src, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the advances in font units, the glyphs have no outlines so they come from HVAR
+	tests := map[string]struct {
+		weight   float64
+		expected []float64
+	}{
+		"Light":   {300, []float64{644, 693, 780, 917}},
+		"Default": {400, []float64{661, 727, 772, 957}},
+		"Between": {550, []float64{680.47, 746.14, 773.65, 980.76}},
+		"Bold":    {700, []float64{720, 785, 777, 1029}},
+		"Clamped": {1000, []float64{720, 785, 777, 1029}},
+	}
+
+	upem := float64(selawik.OpenType.UnitsPerEm())
+
+	for name, tt := range tests {
+		face, err := NewVariableFace(selawik, upem, []Variation{{"wght", tt.weight}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, r := range "AHOW" {
+			advance, ok := face.GlyphAdvance(r)
+			if !ok || math.Abs(unfix(advance)-tt.expected[i]) > 1.0/64 {
+				t.Errorf("%v: %q Expected [%v]\nActual [%v]", name, r, tt.expected[i], unfix(advance))
+			}
+		}
+	}
+
+	// faces can be shared between goroutines
+	face, err := NewVariableFace(selawik, upem, []Variation{{"wght", 700}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, r := range "Selawik" {
+				face.GlyphAdvance(r)
+				face.GlyphBounds(r)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDrawVariableRune(t *testing.T) {
+	registry := NewRegistry()
+	univers, err := registry.RegisterSource(variableUnivers(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dark := make(map[float64]int)
+	for _, weight := range []float64{400, 900} {
+		tf := TypeFace{
+			Name:       "variable",
+			Size:       34,
+			Color:      color.RGBA{0x00, 0x00, 0x00, 0xFF},
+			FontData:   univers.FontData(),
+			FontCache:  registry,
+			Backend:    BackendOpenType,
+			Variations: []Variation{{"wght", weight}},
+		}
+		tf, err = ResizeTypeFace(tf, 34)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		m := image.NewRGBA(image.Rect(0, 0, 100, 80))
+		draw.Draw(m, m.Bounds(), &image.Uniform{color.White}, image.Point{0, 0}, draw.Src)
+
+		gc := draw2dimg.NewGraphicContext(m)
+		registry.Attach(gc)
+		SetFont(gc, tf)
+
+		if err := DrawRune(gc, tf, []float64{10, 50}, 0, 'M'); err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 0; i < len(m.Pix); i += 4 {
+			if m.Pix[i] < 0x80 {
+				dark[weight]++
+			}
+		}
+	}
+
+	if dark[400] == 0 || float64(dark[900]) < float64(dark[400])*1.15 {
+		t.Errorf("Expected more than [%v] dark pixels\nActual [%v]", float64(dark[400])*1.15, dark[900])
+	}
+
+	tf := TypeFace{FontData: draw2d.FontData{Name: "bold"}, Variations: []Variation{{"wght", 900}}}
+	if _, err = ResizeTypeFace(tf, 17); !errors.Is(err, ErrFontNotSupported) {
+		t.Errorf("Expected [%v]\nActual [%v]", ErrFontNotSupported, err)
+	}
+}
+
+func TestTupleScalar(t *testing.T) {
+	tests := map[string]struct {
+		coords, peak, start, end []float64
+		expected                 float64
+	}{
+		"At Peak":             {[]float64{1}, []float64{1}, nil, nil, 1},
+		"Half Way":            {[]float64{0.5}, []float64{1}, nil, nil, 0.5},
+		"Other Side":          {[]float64{-0.5}, []float64{1}, nil, nil, 0},
+		"Default":             {[]float64{0}, []float64{1}, nil, nil, 0},
+		"Past Peak":           {[]float64{0.75}, []float64{0.5}, nil, nil, 0},
+		"Unused Axis":         {[]float64{0.5, 0.25}, []float64{1, 0}, nil, nil, 0.5},
+		"Two Axes":            {[]float64{0.5, -0.5}, []float64{1, -1}, nil, nil, 0.25},
+		"Intermediate Rising": {[]float64{0.4}, []float64{0.5}, []float64{0.3}, []float64{1}, 0.5},
+		"Intermediate Falling": {
+			[]float64{0.75}, []float64{0.5}, []float64{0.3}, []float64{1}, 0.5,
+		},
+		"Intermediate Outside": {[]float64{0.2}, []float64{0.5}, []float64{0.3}, []float64{1}, 0},
+	}
+
+	for name, tt := range tests {
+		if actual := tupleScalar(tt.coords, tt.peak, tt.start, tt.end); math.Abs(actual-tt.expected) > 1e-9 {
+			t.Errorf("%v: Expected [%v]\nActual [%v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestInterpolateUntouched(t *testing.T) {
+	points := []glyphPoint{{x: 0, y: 0}, {x: 50, y: 0}, {x: 100, y: 0}, {x: 100, y: 100}, {x: 0, y: 100}}
+	moved := []bool{true, false, true, false, false}
+	dx := []float64{10, 0, 20, 0, 0}
+	dy := []float64{0, 0, -10, 0, 0}
+
+	interpolateUntouched(points, []int{4}, moved, dx, dy)
+
+	expectedX := []float64{10, 15, 20, 20, 10}
+	// the touched points either side of the top are level but move differently, so it stays put
+	expectedY := []float64{0, 0, -10, 0, 0}
+	if !reflect.DeepEqual(dx, expectedX) || !reflect.DeepEqual(dy, expectedY) {
+		t.Errorf("Expected [%v %v]\nActual [%v %v]", expectedX, expectedY, dx, dy)
+	}
+}
+
+func TestNormalise(t *testing.T) {
+	vf := &variableFont{
+		axes: []Axis{{Tag: "wght", Min: 100, Default: 400, Max: 900}, {Tag: "wdth", Min: 50, Default: 100, Max: 100}},
+		segmentMaps: [][][2]float64{
+			{{-1, -1}, {0, 0}, {0.5, 0.75}, {1, 1}},
+		},
+	}
+
+	tests := map[string]struct {
+		variations []Variation
+		expected   []float64
+	}{
+		"Default":   {nil, []float64{0, 0}},
+		"Light":     {[]Variation{{"wght", 250}}, []float64{-0.5, 0}},
+		"Mapped":    {[]Variation{{"wght", 650}}, []float64{0.75, 0}},
+		"Condensed": {[]Variation{{"wdth", 75}}, []float64{0, -0.5}},
+		"Clamped":   {[]Variation{{"wdth", 25}, {"ital", 1}}, []float64{0, -1}},
+	}
+
+	for name, tt := range tests {
+		if actual := vf.normalise(tt.variations); !reflect.DeepEqual(actual, tt.expected) {
+			t.Errorf("%v: Expected [%v]\nActual [%v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func closeFixed(a, b fixed.Int26_6) bool {
+	return math.Abs(float64(a-b)) <= 1
+}
+
+// variableUnivers makes Univers into a variable font with a weight axis from 100 to 900, the
+// M getting a quarter wider at 900 and the ascent 100 units higher.
+func variableUnivers(t *testing.T) []byte {
+	t.Helper()
+
+	otf, err := sfnt.Parse(ttf.Univers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := otf.GlyphIndex(nil, 'M')
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vf := &variableFont{
+		glyf:        fontTable(ttf.Univers, 0, "glyf"),
+		loca:        fontTable(ttf.Univers, 0, "loca"),
+		locaLong:    binary.BigEndian.Uint16(fontTable(ttf.Univers, 0, "head")[50:]) != 0,
+		hmtx:        fontTable(ttf.Univers, 0, "hmtx"),
+		numHMetrics: int(binary.BigEndian.Uint16(fontTable(ttf.Univers, 0, "hhea")[34:])),
+	}
+	outline, err := vf.outline(int(m), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fvar, one axis and no named instances
+	fvar := make([]byte, 16+20)
+	put16(fvar, 1, 0, 16, 2, 1, 20, 0, 8)
+	copy(fvar[16:], "wght")
+	put32(fvar[20:], 100<<16, 400<<16, 900<<16)
+
+	// gvar, one tuple for the M moving every point, stretching it around the origin
+	deltas := []int16{}
+	for _, p := range outline.points {
+		deltas = append(deltas, int16(math.Round(p.x/4)))
+	}
+	deltas = append(deltas, 0, int16(math.Round(outline.advance/4)), 0, 0)
+
+	tuple := []byte{0}
+	for i := 0; i < len(deltas); i += 64 {
+		run := deltas[i:]
+		if len(run) > 64 {
+			run = run[:64]
+		}
+		tuple = append(tuple, 0x40|byte(len(run)-1))
+		for _, d := range run {
+			tuple = append(tuple, byte(uint16(d)>>8), byte(d))
+		}
+	}
+	for i := 0; i < len(deltas); i += 64 {
+		run := len(deltas) - i
+		if run > 64 {
+			run = 64
+		}
+		tuple = append(tuple, 0x80|byte(run-1))
+	}
+
+	glyphVariations := make([]byte, 10)
+	put16(glyphVariations, 1, 10, uint16(len(tuple)), 0x8000|0x2000, 0x4000)
+	glyphVariations = append(glyphVariations, tuple...)
+
+	numGlyphs := otf.NumGlyphs()
+	gvar := make([]byte, 20+4*(numGlyphs+1))
+	put16(gvar, 1, 0, 1, 0)
+	put32(gvar[8:], uint32(len(gvar)))
+	put16(gvar[12:], uint16(numGlyphs), 1)
+	put32(gvar[16:], uint32(len(gvar)))
+	for g := 0; g <= numGlyphs; g++ {
+		offset := 0
+		if g > int(m) {
+			offset = len(glyphVariations)
+		}
+		put32(gvar[20+4*g:], uint32(offset))
+	}
+	gvar = append(gvar, glyphVariations...)
+
+	// MVAR, the ascent goes up 100 units in the only region
+	mvar := make([]byte, 20+12+10+10)
+	put16(mvar, 1, 0, 0, 8, 1, 20)
+	copy(mvar[12:], "hasc")
+	put16(mvar[20:], 1)
+	put32(mvar[22:], 12)
+	put16(mvar[26:], 1)
+	put32(mvar[28:], 22)
+	put16(mvar[32:], 1, 1, 0, 0x4000, 0x4000)
+	put16(mvar[42:], 1, 1, 1, 0, 100)
+
+	return withTables(t, ttf.Univers, map[string][]byte{"fvar": fvar, "gvar": gvar, "MVAR": mvar})
+}
+
+// withTables returns the font src with the tables added to it.
+func withTables(t *testing.T, src []byte, extra map[string][]byte) []byte {
+	t.Helper()
+
+	tables := make(map[string][]byte)
+	numTables := int(binary.BigEndian.Uint16(src[4:]))
+	for i := 0; i < numTables; i++ {
+		tag := string(src[12+16*i : 16+16*i])
+		tables[tag] = fontTable(src, 0, tag)
+	}
+	for tag, table := range extra {
+		tables[tag] = table
+	}
+
+	tags := []string{}
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	out := make([]byte, 12+16*len(tags))
+	copy(out, src[:4])
+	put16(out[4:], uint16(len(tags)))
+
+	for i, tag := range tags {
+		entry := out[12+16*i:]
+		copy(entry, tag)
+		put32(entry[8:], uint32(len(out)), uint32(len(tables[tag])))
+
+		out = append(out, tables[tag]...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+
+	return out
+}
+
+func put16(b []byte, values ...uint16) {
+	for i, v := range values {
+		binary.BigEndian.PutUint16(b[2*i:], v)
+	}
+}
+
+func put32(b []byte, values ...uint32) {
+	for i, v := range values {
+		binary.BigEndian.PutUint32(b[4*i:], v)
+	}
+}
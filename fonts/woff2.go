@@ -0,0 +1,593 @@
+package fonts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/andybalholm/brotli"
+)
+
+// the tables WOFF2 has a short code for, in the order of the codes.
+var woff2Tags = [...]string{
+	"cmap", "head", "hhea", "hmtx", "maxp", "name", "OS/2", "post", "cvt ", "fpgm",
+	"glyf", "loca", "prep", "CFF ", "VORG", "EBDT", "EBLC", "gasp", "hdmx", "kern",
+	"LTSH", "PCLT", "VDMX", "vhea", "vmtx", "BASE", "GDEF", "GPOS", "GSUB", "EBSC",
+	"JSTF", "MATH", "CBDT", "CBLC", "COLR", "CPAL", "SVG ", "sbix", "acnt", "avar",
+	"bdat", "bloc", "bsln", "cvar", "fdsc", "feat", "fmtx", "fvar", "gvar", "hsty",
+	"just", "lcar", "mort", "morx", "opbd", "prop", "trak", "Zapf", "Silf", "Glat",
+	"Gloc", "Feat", "Sill",
+}
+
+// the flags of the points and components of glyf glyphs.
+const (
+	glyfOnCurve        = 0x01
+	glyfXShort         = 0x02
+	glyfYShort         = 0x04
+	glyfXSame          = 0x10
+	glyfYSame          = 0x20
+	glyfOverlapSimple  = 0x40
+	glyfArgsAreWords   = 0x0001
+	glyfHaveScale      = 0x0008
+	glyfMoreComponents = 0x0020
+	glyfHaveXYScale    = 0x0040
+	glyfHaveTwoByTwo   = 0x0080
+	glyfHaveInstrs     = 0x0100
+)
+
+// unpackWOFF2 decompresses a WOFF2 font, or collection, and undoes the transforms of its
+// glyf, loca and hmtx tables to give back a font that works the same as the one it was made
+// from. The tables can come out different byte for byte, as the glyphs are encoded again.
+// https://www.w3.org/TR/WOFF2/
+func unpackWOFF2(src []byte) ([]byte, error) {
+	const headerSize = 48
+
+	if len(src) < headerSize {
+		return nil, fmt.Errorf("%w (woff2 header is too short)", ErrFontNotSupported)
+	}
+
+	flavor := src[4:8]
+	numTables := int(binary.BigEndian.Uint16(src[12:]))
+	compressedSize := int(binary.BigEndian.Uint32(src[20:]))
+
+	type entry struct {
+		tag         string
+		transformed bool
+		origLength  int
+		length      int
+	}
+	entries := make([]entry, numTables)
+
+	r := woff2Reader{data: src[headerSize:]}
+	for i := range entries {
+		flags := r.u8()
+
+		e := entry{}
+		if code := flags & 0x3f; code < len(woff2Tags) {
+			e.tag = woff2Tags[code]
+		} else {
+			e.tag = string(r.bytes(4))
+		}
+
+		// glyf and loca are transformed unless they say otherwise, the rest the other way round
+		version := flags >> 6
+		if e.tag == "glyf" || e.tag == "loca" {
+			e.transformed = version == 0
+		} else {
+			e.transformed = version != 0
+		}
+
+		e.origLength = r.base128()
+		e.length = e.origLength
+		if e.transformed {
+			e.length = r.base128()
+		}
+
+		entries[i] = e
+	}
+
+	all := make([]int, numTables)
+	for i := range all {
+		all[i] = i
+	}
+	fonts := []sfntFont{{flavor: flavor, tables: all}}
+
+	collection := string(flavor) == tagCollection
+	if collection {
+		r.u32() // the version
+		fonts = make([]sfntFont, r.u255())
+		for i := range fonts {
+			indexes := make([]int, r.u255())
+			fonts[i].flavor = r.bytes(4)
+			for j := range indexes {
+				indexes[j] = r.u255()
+				if indexes[j] >= numTables {
+					return nil, fmt.Errorf("%w (woff2 collection table index)", ErrFontNotSupported)
+				}
+			}
+			fonts[i].tables = indexes
+		}
+	}
+
+	compressed := r.bytes(compressedSize)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	// the tables one after the other, reading no more than they need
+	var size, origSize int64
+	for _, e := range entries {
+		size += int64(e.length)
+		origSize += int64(e.origLength)
+	}
+	if size > maxFontSize || origSize > maxFontSize {
+		return nil, fmt.Errorf("%w (woff2 tables are too large)", ErrFontNotSupported)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(brotli.NewReader(bytes.NewReader(compressed)), size))
+	if err != nil {
+		return nil, fmt.Errorf("%w (woff2 data: %v)", ErrFontNotSupported, err)
+	}
+	if int64(len(data)) != size {
+		return nil, fmt.Errorf("%w (woff2 data is too short)", ErrFontNotSupported)
+	}
+
+	tables := make([]sfntTable, numTables)
+	for i, e := range entries {
+		tables[i] = sfntTable{tag: e.tag, data: data[:e.length]}
+		data = data[e.length:]
+	}
+
+	// undo the transforms of each font, glyf first as hmtx needs the glyphs
+	done := map[int]bool{}
+	xMins := map[int][]int{}
+	for _, f := range fonts {
+		index := map[string]int{}
+		for _, t := range f.tables {
+			index[tables[t].tag] = t
+		}
+
+		glyf, hasGlyf := index["glyf"]
+		loca, hasLoca := index["loca"]
+		if hasGlyf && entries[glyf].transformed && !done[glyf] {
+			if !hasLoca || !entries[loca].transformed {
+				return nil, fmt.Errorf("%w (woff2 glyf is transformed but loca isnt)", ErrFontNotSupported)
+			}
+
+			glyfData, locaData, glyphXMins, err := reconstructGlyf(tables[glyf].data)
+			if err != nil {
+				return nil, err
+			}
+			if len(locaData) != entries[loca].origLength {
+				return nil, fmt.Errorf("%w (woff2 table %q is the wrong length)", ErrFontNotSupported, "loca")
+			}
+
+			tables[glyf].data = glyfData
+			tables[loca].data = locaData
+			xMins[glyf] = glyphXMins
+			done[glyf], done[loca] = true, true
+		}
+
+		hmtx, hasHmtx := index["hmtx"]
+		if hasHmtx && entries[hmtx].transformed && !done[hmtx] {
+			hhea := tables[index["hhea"]].data
+			if xMins[glyf] == nil || len(hhea) < 36 {
+				return nil, fmt.Errorf("%w (woff2 hmtx is transformed without glyf)", ErrFontNotSupported)
+			}
+
+			numHMetrics := int(binary.BigEndian.Uint16(hhea[34:]))
+			hmtxData, err := reconstructHmtx(tables[hmtx].data, numHMetrics, xMins[glyf])
+			if err != nil {
+				return nil, err
+			}
+
+			tables[hmtx].data = hmtxData
+			done[hmtx] = true
+		}
+	}
+
+	for i, e := range entries {
+		if e.transformed && !done[i] {
+			return nil, fmt.Errorf("%w (woff2 transform of %q)", ErrFontNotSupported, e.tag)
+		}
+	}
+
+	return writeSFNT(fonts, tables, collection), nil
+}
+
+// reconstructGlyf turns the streams of a transformed glyf table back into glyf and loca
+// tables, and returns the xMin of each glyph for hmtx.
+func reconstructGlyf(src []byte) ([]byte, []byte, []int, error) {
+	r := woff2Reader{data: src}
+	r.u16() // the version
+	options := r.u16()
+	numGlyphs := r.u16()
+	indexFormat := r.u16()
+
+	var sizes [7]int
+	for i := range sizes {
+		sizes[i] = r.u32()
+	}
+
+	nContours := woff2Reader{data: r.bytes(sizes[0])}
+	nPoints := woff2Reader{data: r.bytes(sizes[1])}
+	flags := woff2Reader{data: r.bytes(sizes[2])}
+	glyphs := woff2Reader{data: r.bytes(sizes[3])}
+	composites := woff2Reader{data: r.bytes(sizes[4])}
+	bboxes := woff2Reader{data: r.bytes(sizes[5])}
+	instructions := woff2Reader{data: r.bytes(sizes[6])}
+
+	bboxBitmap := bboxes.bytes(4 * ((numGlyphs + 31) / 32))
+	var overlapBitmap []byte
+	if options&1 != 0 {
+		overlapBitmap = r.bytes((numGlyphs + 7) / 8)
+	}
+	if r.err != nil {
+		return nil, nil, nil, r.err
+	}
+	if bboxes.err != nil {
+		return nil, nil, nil, bboxes.err
+	}
+
+	var glyf []byte
+	offsets := make([]int, numGlyphs+1)
+	xMins := make([]int, numGlyphs)
+
+	for g := 0; g < numGlyphs; g++ {
+		offsets[g] = len(glyf)
+
+		bit := byte(0x80 >> (g & 7))
+		hasBBox := bboxBitmap[g>>3]&bit != 0
+		overlap := overlapBitmap != nil && overlapBitmap[g>>3]&bit != 0
+
+		var bbox [4]int
+		if hasBBox {
+			for i := range bbox {
+				bbox[i] = int(int16(bboxes.u16()))
+			}
+		}
+
+		n := int(int16(nContours.u16()))
+		switch {
+		case n == 0:
+			if hasBBox {
+				return nil, nil, nil, fmt.Errorf("%w (woff2 empty glyph %d has a bounding box)", ErrFontNotSupported, g)
+			}
+			continue
+
+		case n > 0:
+			glyf = appendSimpleGlyph(glyf, n, bbox, hasBBox, overlap, &nPoints, &flags, &glyphs, &instructions)
+
+		case n == -1:
+			if !hasBBox {
+				return nil, nil, nil, fmt.Errorf("%w (woff2 composite glyph %d has no bounding box)", ErrFontNotSupported, g)
+			}
+			glyf = appendCompositeGlyph(glyf, bbox, &composites, &glyphs, &instructions)
+
+		default:
+			return nil, nil, nil, fmt.Errorf("%w (woff2 glyph %d has %d contours)", ErrFontNotSupported, g, n)
+		}
+
+		xMins[g] = int(int16(binary.BigEndian.Uint16(glyf[offsets[g]+2:])))
+		for len(glyf)%4 != 0 {
+			glyf = append(glyf, 0)
+		}
+	}
+	offsets[numGlyphs] = len(glyf)
+
+	for _, stream := range []woff2Reader{nContours, nPoints, flags, glyphs, composites, bboxes, instructions} {
+		if stream.err != nil {
+			return nil, nil, nil, stream.err
+		}
+	}
+
+	var loca []byte
+	for _, offset := range offsets {
+		if indexFormat == 0 {
+			loca = appendUint16(loca, offset/2)
+		} else {
+			loca = appendUint32(loca, offset)
+		}
+	}
+
+	return glyf, loca, xMins, nil
+}
+
+// appendSimpleGlyph reads a glyph with n contours from the streams and appends it to glyf.
+// Without a bounding box one is worked out from the points.
+func appendSimpleGlyph(glyf []byte, n int, bbox [4]int, hasBBox, overlap bool, nPoints, flags, glyphs, instructions *woff2Reader) []byte {
+	endPts := make([]int, n)
+	numPoints := 0
+	for i := range endPts {
+		numPoints += nPoints.u255()
+		endPts[i] = numPoints - 1
+	}
+
+	var pointFlags, xs, ys []byte
+	var x, y int
+	for i := 0; i < numPoints && flags.err == nil; i++ {
+		f := flags.u8()
+		dx, dy := woff2Triplet(f&0x7f, glyphs)
+		x += dx
+		y += dy
+
+		if !hasBBox {
+			if i == 0 || x < bbox[0] {
+				bbox[0] = x
+			}
+			if i == 0 || y < bbox[1] {
+				bbox[1] = y
+			}
+			if i == 0 || x > bbox[2] {
+				bbox[2] = x
+			}
+			if i == 0 || y > bbox[3] {
+				bbox[3] = y
+			}
+		}
+
+		pf := byte(0)
+		if f&0x80 == 0 {
+			pf |= glyfOnCurve
+		}
+		if overlap && i == 0 {
+			pf |= glyfOverlapSimple
+		}
+
+		var xf, yf byte
+		xs, xf = appendCoordinate(xs, dx, glyfXShort, glyfXSame)
+		ys, yf = appendCoordinate(ys, dy, glyfYShort, glyfYSame)
+		pointFlags = append(pointFlags, pf|xf|yf)
+	}
+
+	instructionLength := glyphs.u255()
+
+	glyf = appendGlyphHeader(glyf, n, bbox)
+	for _, e := range endPts {
+		glyf = appendUint16(glyf, e)
+	}
+	glyf = appendUint16(glyf, instructionLength)
+	glyf = append(glyf, instructions.bytes(instructionLength)...)
+	glyf = append(glyf, pointFlags...)
+	glyf = append(glyf, xs...)
+
+	return append(glyf, ys...)
+}
+
+// appendCompositeGlyph reads a composite glyph from the streams and appends it to glyf.
+func appendCompositeGlyph(glyf []byte, bbox [4]int, composites, glyphs, instructions *woff2Reader) []byte {
+	glyf = appendGlyphHeader(glyf, -1, bbox)
+
+	haveInstructions := false
+	for composites.err == nil {
+		flags := composites.u16()
+
+		// the glyph index and the arguments, then the scale
+		size := 4
+		if flags&glyfArgsAreWords != 0 {
+			size = 6
+		}
+		switch {
+		case flags&glyfHaveScale != 0:
+			size += 2
+		case flags&glyfHaveXYScale != 0:
+			size += 4
+		case flags&glyfHaveTwoByTwo != 0:
+			size += 8
+		}
+
+		glyf = appendUint16(glyf, flags)
+		glyf = append(glyf, composites.bytes(size)...)
+
+		if flags&glyfHaveInstrs != 0 {
+			haveInstructions = true
+		}
+		if flags&glyfMoreComponents == 0 {
+			break
+		}
+	}
+
+	if haveInstructions {
+		instructionLength := glyphs.u255()
+		glyf = appendUint16(glyf, instructionLength)
+		glyf = append(glyf, instructions.bytes(instructionLength)...)
+	}
+
+	return glyf
+}
+
+func appendGlyphHeader(glyf []byte, n int, bbox [4]int) []byte {
+	glyf = appendUint16(glyf, n)
+	for _, b := range bbox {
+		glyf = appendUint16(glyf, b)
+	}
+
+	return glyf
+}
+
+// appendCoordinate appends the change in a coordinate as glyf stores it, in a byte when it
+// fits or not at all when it is nothing, and returns the flags saying which.
+func appendCoordinate(coords []byte, d int, short, same byte) ([]byte, byte) {
+	switch {
+	case d == 0:
+		return coords, same
+	case d > 0 && d < 256:
+		return append(coords, byte(d)), short | same
+	case d < 0 && d > -256:
+		return append(coords, byte(-d)), short
+	}
+
+	return appendUint16(coords, d), 0
+}
+
+// woff2Triplet reads the change in x and y to a point, the flag says how it is encoded.
+func woff2Triplet(flag int, r *woff2Reader) (int, int) {
+	withSign := func(flag, v int) int {
+		if flag&1 != 0 {
+			return v
+		}
+		return -v
+	}
+
+	switch {
+	case flag < 10:
+		return 0, withSign(flag, ((flag&14)<<7)+r.u8())
+
+	case flag < 20:
+		return withSign(flag, (((flag-10)&14)<<7)+r.u8()), 0
+
+	case flag < 84:
+		b0, b1 := flag-20, r.u8()
+		return withSign(flag, 1+(b0&0x30)+(b1>>4)), withSign(flag>>1, 1+((b0&0x0c)<<2)+(b1&0x0f))
+
+	case flag < 120:
+		b0 := flag - 84
+		b1 := r.u8()
+		b2 := r.u8()
+		return withSign(flag, 1+((b0/12)<<8)+b1), withSign(flag>>1, 1+(((b0%12)>>2)<<8)+b2)
+
+	case flag < 124:
+		b1 := r.u8()
+		b2 := r.u8()
+		b3 := r.u8()
+		return withSign(flag, (b1<<4)+(b2>>4)), withSign(flag>>1, ((b2&0x0f)<<8)+b3)
+	}
+
+	b1 := r.u8()
+	b2 := r.u8()
+	b3 := r.u8()
+	b4 := r.u8()
+
+	return withSign(flag, (b1<<8)+b2), withSign(flag>>1, (b3<<8)+b4)
+}
+
+// reconstructHmtx puts back the left side bearings a transformed hmtx table leaves out,
+// they are the same as the xMin of each glyph.
+func reconstructHmtx(src []byte, numHMetrics int, xMins []int) ([]byte, error) {
+	numGlyphs := len(xMins)
+	if numHMetrics < 1 || numHMetrics > numGlyphs {
+		return nil, fmt.Errorf("%w (woff2 hmtx has %d metrics for %d glyphs)", ErrFontNotSupported, numHMetrics, numGlyphs)
+	}
+
+	r := woff2Reader{data: src}
+	flags := r.u8()
+
+	advances := make([]int, numHMetrics)
+	for i := range advances {
+		advances[i] = r.u16()
+	}
+
+	// the proportional glyphs then the monospaced ones
+	lsbs := make([]int, numGlyphs)
+	for g := range lsbs {
+		if g < numHMetrics && flags&1 == 0 || g >= numHMetrics && flags&2 == 0 {
+			lsbs[g] = int(int16(r.u16()))
+		} else {
+			lsbs[g] = xMins[g]
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	var hmtx []byte
+	for g, lsb := range lsbs {
+		if g < numHMetrics {
+			hmtx = appendUint16(hmtx, advances[g])
+		}
+		hmtx = appendUint16(hmtx, lsb)
+	}
+
+	return hmtx, nil
+}
+
+// appendUint16 appends the low 16 bits of v, big endian.
+func appendUint16(b []byte, v int) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+// appendUint32 appends the low 32 bits of v, big endian.
+func appendUint32(b []byte, v int) []byte {
+	return append(b, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// woff2Reader reads through WOFF2 data, remembering if it runs out.
+type woff2Reader struct {
+	data []byte
+	err  error
+}
+
+func (r *woff2Reader) bytes(n int) []byte {
+	if r.err == nil && (n < 0 || n > len(r.data)) {
+		r.err = fmt.Errorf("%w (woff2 data is too short)", ErrFontNotSupported)
+	}
+	if r.err != nil {
+		return nil
+	}
+
+	b := r.data[:n]
+	r.data = r.data[n:]
+
+	return b
+}
+
+func (r *woff2Reader) u8() int {
+	if b := r.bytes(1); r.err == nil {
+		return int(b[0])
+	}
+
+	return 0
+}
+
+func (r *woff2Reader) u16() int {
+	if b := r.bytes(2); r.err == nil {
+		return int(binary.BigEndian.Uint16(b))
+	}
+
+	return 0
+}
+
+func (r *woff2Reader) u32() int {
+	if b := r.bytes(4); r.err == nil {
+		return int(binary.BigEndian.Uint32(b))
+	}
+
+	return 0
+}
+
+// base128 reads a UIntBase128, seven bits to a byte with the top bit set on all but the last.
+func (r *woff2Reader) base128() int {
+	var v uint32
+	for i := 0; i < 5; i++ {
+		b := r.u8()
+		if r.err != nil || (i == 0 && b == 0x80) || v&0xfe000000 != 0 {
+			break
+		}
+
+		v = v<<7 | uint32(b&0x7f)
+		if b&0x80 == 0 {
+			return int(v)
+		}
+	}
+
+	if r.err == nil {
+		r.err = fmt.Errorf("%w (woff2 number)", ErrFontNotSupported)
+	}
+
+	return 0
+}
+
+// u255 reads a 255UInt16, a byte for small numbers with codes for bigger ones.
+func (r *woff2Reader) u255() int {
+	switch code := r.u8(); code {
+	case 253:
+		return r.u16()
+	case 254:
+		return r.u8() + 253*2
+	case 255:
+		return r.u8() + 253
+	default:
+		return code
+	}
+}
@@ -0,0 +1,184 @@
+package fonts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestRegisterWOFF2(t *testing.T) {
+	registry := NewRegistry()
+
+	// Open Sans, with its glyf and loca tables transformed
+	loaded, err := registry.LoadDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var openSans RegisteredFont
+	for _, f := range loaded {
+		if f.Name == "open-sans-regular" {
+			openSans = f
+		}
+	}
+	if openSans.Font == nil || openSans.OpenType == nil {
+		t.Fatalf("Expected [open-sans-regular] for both backends\nActual [%+v]", loaded)
+	}
+
+	// every glyph can be drawn by both backends
+	var buf sfnt.Buffer
+	var glyph truetype.GlyphBuf
+	for i := 0; i < openSans.OpenType.NumGlyphs(); i++ {
+		if _, err := openSans.OpenType.LoadGlyph(&buf, sfnt.GlyphIndex(i), fixed.I(34), nil); err != nil {
+			t.Fatalf("glyph %v: %v", i, err)
+		}
+		if err := glyph.Load(openSans.Font, fixed.I(34), truetype.Index(i), font.HintingNone); err != nil {
+			t.Fatalf("glyph %v: %v", i, err)
+		}
+	}
+
+	face, err := GetOpenTypeFace(registry, openSans.FontData(), 34)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := GetGlyphWidth(TypeFace{Name: "open-sans", Size: 34, Face: newFace(openSans.Font, openSans.Source, 34)}, 'M')
+	actual := GetGlyphWidth(TypeFace{Name: "open-sans-opentype", Size: 34, Face: face, Backend: BackendOpenType}, 'M')
+	if expected != actual || actual == 0 {
+		t.Errorf("Expected [%v]\nActual [%v]", expected, actual)
+	}
+
+	if _, err = registry.RegisterFonts(packWOFF2(t, ttf.Arial)[:100]); !errors.Is(err, ErrFontNotSupported) {
+		t.Errorf("Expected [%v]\nActual [%v]", ErrFontNotSupported, err)
+	}
+}
+
+func TestRegisterWOFF2Collection(t *testing.T) {
+	registry := NewRegistry()
+	fonts, err := registry.RegisterFonts(packWOFF2(t, ttf.Univers, ttf.UniversBold))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, f := range fonts {
+		names = append(names, f.Name)
+	}
+	if len(names) != 2 || names[0] != "univers-regular" || names[1] != "univers-bold" {
+		t.Fatalf("Expected [univers-regular univers-bold]\nActual [%+v]", names)
+	}
+
+	for _, tag := range []string{"name", "glyf", "loca", "hmtx"} {
+		if !bytes.Equal(fontTable(fonts[1].Source, collectionOffsets(fonts[1].Source)[1], tag), fontTable(ttf.UniversBold, 0, tag)) {
+			t.Errorf("Expected the %v table to be unpacked", tag)
+		}
+	}
+}
+
+func TestReconstructHmtx(t *testing.T) {
+	xMins := []int{10, -20, 30, 40}
+
+	tests := map[string]struct {
+		src      []byte
+		expected []int
+	}{
+		"Proportional": {
+			// two advances, the monospaced left side bearings
+			[]byte{0x01, 0x01, 0x00, 0x02, 0x00, 0x00, 0x05, 0xff, 0xfb},
+			[]int{256, 10, 512, -20, 5, -5},
+		},
+		"Both": {
+			[]byte{0x03, 0x01, 0x00, 0x02, 0x00},
+			[]int{256, 10, 512, -20, 30, 40},
+		},
+	}
+
+	for name, tt := range tests {
+		hmtx, err := reconstructHmtx(tt.src, 2, xMins)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		actual := []int{}
+		for i := 0; i+2 <= len(hmtx); i += 2 {
+			actual = append(actual, int(int16(binary.BigEndian.Uint16(hmtx[i:]))))
+		}
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+}
+
+// packWOFF2 compresses the fonts into a WOFF2 font, or a collection when there is more
+// than one, leaving the tables as they are.
+func packWOFF2(t *testing.T, srcs ...[]byte) []byte {
+	t.Helper()
+
+	var directory, collection, data []byte
+	numTables := 0
+
+	for _, src := range srcs {
+		n := int(binary.BigEndian.Uint16(src[4:]))
+		collection = append(collection, byte(n))
+		collection = append(collection, src[:4]...)
+
+		for i := 0; i < n; i++ {
+			entry := src[12+16*i:]
+			offset := binary.BigEndian.Uint32(entry[8:])
+			length := binary.BigEndian.Uint32(entry[12:])
+
+			// an arbitrary tag, glyf and loca say they arent transformed
+			flags := byte(0x3f)
+			if tag := string(entry[:4]); tag == "glyf" || tag == "loca" {
+				flags |= 3 << 6
+			}
+			directory = append(directory, flags)
+			directory = append(directory, entry[:4]...)
+			for shift := 28; shift > 0; shift -= 7 {
+				if length >= 1<<shift {
+					directory = append(directory, byte(length>>shift)&0x7f|0x80)
+				}
+			}
+			directory = append(directory, byte(length&0x7f))
+
+			collection = append(collection, byte(numTables))
+			data = append(data, src[offset:offset+length]...)
+			numTables++
+		}
+	}
+
+	var compressed bytes.Buffer
+	bw := brotli.NewWriter(&compressed)
+	if _, err := bw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	header := make([]byte, 48)
+	copy(header, "wOF2")
+	copy(header[4:], srcs[0][:4])
+	if len(srcs) > 1 {
+		copy(header[4:], "ttcf")
+		directory = append(directory, 0x00, 0x01, 0x00, 0x00, byte(len(srcs)))
+		directory = append(directory, collection...)
+	}
+	binary.BigEndian.PutUint16(header[12:], uint16(numTables))
+	binary.BigEndian.PutUint32(header[20:], uint32(compressed.Len()))
+
+	out := append(header, directory...)
+
+	return append(out, compressed.Bytes()...)
+}
@@ -3,9 +3,12 @@ module github.com/rockwell-uk/go-text
 go 1.18
 
 require (
+	github.com/andybalholm/brotli v1.0.5
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0
 	github.com/llgcode/draw2d v0.0.0-20210904075650-80aa0a2a901d
 	github.com/rockwell-uk/csync v1.0.0
 	github.com/rockwell-uk/go-draw v1.0.0
 	golang.org/x/image v0.6.0
 )
+
+require golang.org/x/text v0.8.0 // indirect
@@ -0,0 +1,219 @@
+package text
+
+import (
+	"math"
+	"strings"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+// Align is how the lines of a block of text line up with the sides of the box.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCentre
+	AlignRight
+	// stretch the spaces so every line but the last of each paragraph fills the width of the box
+	AlignJustify
+)
+
+// VerticalAlign is where a block of text goes when it is shorter than the box.
+type VerticalAlign int
+
+const (
+	AlignTop VerticalAlign = iota
+	AlignMiddle
+	AlignBottom
+)
+
+// Box is a rectangle to lay text out in, Y increases down the page.
+type Box struct {
+	X, Y          float64
+	Width, Height float64
+}
+
+// BlockOptions controls how text is laid out in a box.
+type BlockOptions struct {
+	Align         Align
+	VerticalAlign VerticalAlign
+	// the distance between baselines, as a multiple of the font height, defaults to 1
+	LineHeight float64
+	// space left empty inside each side of the box
+	Padding float64
+}
+
+// Block is text laid out in a box.
+type Block struct {
+	// the position of each character, on its baseline, ready for drawing
+	LetterPositions []LetterPosition
+	Lines           []string
+	// the size of the text laid out, without the padding
+	Width, Height float64
+	// some of the text didnt fit in the box, Remaining is the text that didnt fit
+	// below it, lines too wide for the box are laid out anyway
+	Overflow  bool
+	Remaining string
+}
+
+// TextInBox lays the text out in the box, wrapping it onto as many lines as it needs and
+// starting a new paragraph at each newline. The glyphs are drawn the same way as glyphs
+// placed along a line.
+func TextInBox(gc *draw2dimg.GraphicContext, text string, box Box, tf fonts.TypeFace, opts BlockOptions) ([]TextGlyph, Block, error) {
+	block, err := GetLetterPositionsInBox(text, box, tf, opts)
+	if err != nil {
+		return []TextGlyph{}, block, err
+	}
+
+	return toTextGlyphs(block.LetterPositions), block, nil
+}
+
+func GetLetterPositionsInBox(text string, box Box, tf fonts.TypeFace, opts BlockOptions) (Block, error) {
+	block := Block{
+		LetterPositions: []LetterPosition{},
+		Lines:           []string{},
+	}
+
+	fm := fonts.GetFaceMetrics(tf)
+	lineHeight := opts.LineHeight
+	if lineHeight <= 0 {
+		lineHeight = 1
+	}
+	lineHeight *= fm.Height
+
+	inner := Box{
+		X:      box.X + opts.Padding,
+		Y:      box.Y + opts.Padding,
+		Width:  box.Width - 2*opts.Padding,
+		Height: box.Height - 2*opts.Padding,
+	}
+
+	// wrap every paragraph, remembering which lines finish one
+	type blockLine struct {
+		text string
+		last bool
+		// index in text of the start of the line
+		start int
+	}
+	var lines []blockLine
+
+	offset := 0
+	for _, paragraph := range strings.Split(text, "\n") {
+		wrapped, starts := fill(paragraph, tf, inner.Width)
+		for i, l := range wrapped {
+			lines = append(lines, blockLine{text: l, last: i == len(wrapped)-1, start: offset + starts[i]})
+		}
+		offset += len(paragraph) + 1
+	}
+
+	// how many lines fit, the last line has to fit down to its descent
+	fits := len(lines)
+	if n := int(math.Floor((inner.Height-fm.Ascent-fm.Descent)/lineHeight)) + 1; n < fits {
+		fits = int(math.Max(0, float64(n)))
+		block.Overflow = true
+		if fits < len(lines) {
+			block.Remaining = text[lines[fits].start:]
+		}
+	}
+	lines = lines[:fits]
+
+	if len(lines) > 0 {
+		block.Height = float64(len(lines)-1)*lineHeight + fm.Ascent + fm.Descent
+	}
+
+	top := inner.Y
+	switch opts.VerticalAlign {
+	case AlignTop:
+	case AlignMiddle:
+		top += (inner.Height - block.Height) / 2
+	case AlignBottom:
+		top += inner.Height - block.Height
+	}
+
+	for i, l := range lines {
+		charMetrics := getCharMetrics(l.text, tf)
+		width := measure(l.text, tf)
+		if width > inner.Width {
+			block.Overflow = true
+		}
+
+		x := inner.X
+		var stretch float64
+		switch opts.Align {
+		case AlignLeft:
+		case AlignCentre:
+			x += (inner.Width - width) / 2
+		case AlignRight:
+			x += inner.Width - width
+		case AlignJustify:
+			if spaces := strings.Count(l.text, " "); spaces > 0 && !l.last && width < inner.Width {
+				stretch = (inner.Width - width) / float64(spaces)
+				width = inner.Width
+			}
+		}
+
+		y := top + fm.Ascent + float64(i)*lineHeight
+		for _, cm := range charMetrics {
+			block.LetterPositions = append(block.LetterPositions, LetterPosition{
+				Char: cm.Char,
+				X:    x,
+				Y:    y,
+			})
+
+			x += cm.Width
+			if cm.Char == " " {
+				x += stretch
+			}
+		}
+
+		block.Lines = append(block.Lines, l.text)
+		block.Width = math.Max(block.Width, width)
+	}
+
+	return block, nil
+}
+
+// fill puts as many words on each line as fit in maxWidth, a word that is wider
+// than maxWidth gets a line to itself. It returns the lines and where each starts in the paragraph.
+func fill(paragraph string, tf fonts.TypeFace, maxWidth float64) ([]string, []int) {
+	lines := []string{}
+	starts := []int{}
+
+	var line string
+	start := -1
+	for i := 0; i < len(paragraph); {
+		// skip to the next word
+		if paragraph[i] == ' ' {
+			i++
+			continue
+		}
+		end := strings.IndexByte(paragraph[i:], ' ')
+		if end < 0 {
+			end = len(paragraph)
+		} else {
+			end += i
+		}
+		word := paragraph[i:end]
+
+		switch {
+		case start < 0:
+			line, start = word, i
+		case measure(line+" "+word, tf) > maxWidth:
+			lines = append(lines, line)
+			starts = append(starts, start)
+			line, start = word, i
+		default:
+			line += " " + word
+		}
+
+		i = end
+	}
+
+	if start < 0 {
+		start = 0
+	}
+
+	return append(lines, line), append(starts, start)
+}
@@ -0,0 +1,167 @@
+package text
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const fox = "The quick brown fox jumps over the lazy dog\nThe end"
+
+func TestGetLetterPositionsInBox(t *testing.T) {
+	tests := map[string]struct {
+		box       Box
+		opts      BlockOptions
+		lines     []string
+		first     LetterPosition
+		overflow  bool
+		remaining string
+	}{
+		"Top left": {
+			Box{0, 0, 300, 400},
+			BlockOptions{},
+			[]string{"The quick brown", "fox jumps over", "the lazy dog", "The end"},
+			LetterPosition{Char: "T", X: 0, Y: 33.625},
+			false,
+			"",
+		},
+		"Padding": {
+			Box{0, 0, 300, 400},
+			BlockOptions{Padding: 10},
+			[]string{"The quick brown", "fox jumps over", "the lazy dog", "The end"},
+			LetterPosition{Char: "T", X: 10, Y: 43.625},
+			false,
+			"",
+		},
+		"Middle": {
+			Box{0, 0, 300, 400},
+			BlockOptions{VerticalAlign: AlignMiddle},
+			[]string{"The quick brown", "fox jumps over", "the lazy dog", "The end"},
+			LetterPosition{Char: "T", X: 0, Y: 161.5625},
+			false,
+			"",
+		},
+		"Bottom": {
+			Box{0, 0, 300, 400},
+			BlockOptions{VerticalAlign: AlignBottom},
+			[]string{"The quick brown", "fox jumps over", "the lazy dog", "The end"},
+			LetterPosition{Char: "T", X: 0, Y: 289.5},
+			false,
+			"",
+		},
+		"Overflow": {
+			Box{0, 0, 300, 100},
+			BlockOptions{},
+			[]string{"The quick brown", "fox jumps over"},
+			LetterPosition{Char: "T", X: 0, Y: 33.625},
+			true,
+			"the lazy dog\nThe end",
+		},
+	}
+
+	typeFace := ringTypeFace(t)
+
+	for name, tt := range tests {
+		block, err := GetLetterPositionsInBox(fox, tt.box, typeFace, tt.opts)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(tt.lines, block.Lines) {
+			t.Errorf("%v: Expected [%q]\nActual [%q]", name, tt.lines, block.Lines)
+		}
+
+		if !reflect.DeepEqual(tt.first, block.LetterPositions[0]) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.first, block.LetterPositions[0])
+		}
+
+		if block.Overflow != tt.overflow || block.Remaining != tt.remaining {
+			t.Errorf("%v: Expected overflow [%v %q], Got [%v %q]", name, tt.overflow, tt.remaining, block.Overflow, block.Remaining)
+		}
+	}
+}
+
+func TestGetLetterPositionsInBoxAlign(t *testing.T) {
+	typeFace := ringTypeFace(t)
+	box := Box{0, 0, 300, 400}
+
+	// where each line starts and finishes
+	edges := func(block Block) [][]float64 {
+		e := [][]float64{}
+		i := 0
+		for _, l := range block.Lines {
+			charMetrics := getCharMetrics(l, typeFace)
+			last := block.LetterPositions[i+len(charMetrics)-1]
+			e = append(e, []float64{
+				block.LetterPositions[i].X,
+				last.X + charMetrics[len(charMetrics)-1].Width,
+			})
+			i += len(charMetrics)
+		}
+		return e
+	}
+
+	tests := map[string]struct {
+		align Align
+		check func(line string, start, end float64) bool
+	}{
+		"Left": {
+			AlignLeft,
+			func(line string, start, end float64) bool { return start == 0 },
+		},
+		"Right": {
+			AlignRight,
+			func(line string, start, end float64) bool { return math.Abs(end-300) < 1e-9 },
+		},
+		"Centre": {
+			AlignCentre,
+			func(line string, start, end float64) bool { return math.Abs(start-(300-end)) < 1e-9 },
+		},
+		"Justify": {
+			AlignJustify,
+			func(line string, start, end float64) bool {
+				// apart from the last line of each paragraph
+				if strings.HasSuffix(line, "dog") || line == "The end" {
+					return start == 0 && end < 300
+				}
+				return start == 0 && math.Abs(end-300) < 1e-9
+			},
+		},
+	}
+
+	// with and without letter spacing
+	for _, spacing := range []float64{0, 4} {
+		typeFace.Spacing = spacing
+
+		for name, tt := range tests {
+			block, err := GetLetterPositionsInBox(fox, box, typeFace, BlockOptions{Align: tt.align})
+			if err != nil {
+				t.Fatalf("%v: %v", name, err)
+			}
+
+			for i, e := range edges(block) {
+				if !tt.check(block.Lines[i], e[0], e[1]) {
+					t.Errorf("%v: spacing [%v] line [%v] [%v] runs from [%v] to [%v]", name, spacing, i, block.Lines[i], e[0], e[1])
+				}
+			}
+		}
+	}
+}
+
+func TestFill(t *testing.T) {
+	typeFace := ringTypeFace(t)
+
+	lines, starts := fill("  Pilsworth   Road  Bury", typeFace, 200)
+
+	expected := []string{"Pilsworth", "Road Bury"}
+	if !reflect.DeepEqual(expected, lines) {
+		t.Errorf("Expected [%q]\nActual [%q]", expected, lines)
+	}
+
+	for i, s := range starts {
+		if !strings.HasPrefix("  Pilsworth   Road  Bury"[s:], strings.Fields(lines[i])[0]) {
+			t.Errorf("Expected line [%v] to start at [%v]", lines[i], s)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package text
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrTooCurvy is returned when the line bends too sharply under the label for it to be read.
+var ErrTooCurvy = errors.New("the line is too curvy for the label")
+
+// checkCurvature makes sure the angle between the placed characters doesnt change more than the options allow.
+func checkCurvature(label string, letterPositions []LetterPosition, opts LineOptions) error {
+	if opts.MaxCharAngleDelta <= 0 && opts.MaxWindowAngleDelta <= 0 {
+		return nil
+	}
+
+	deltas := angleDeltas(letterPositions)
+
+	if opts.MaxCharAngleDelta > 0 {
+		for i, d := range deltas {
+			if math.Abs(d) > opts.MaxCharAngleDelta {
+				return fmt.Errorf("[%v] %w [%v:%v] (%v > %v)", label, ErrTooCurvy, i, i+1, math.Abs(d), opts.MaxCharAngleDelta)
+			}
+		}
+	}
+
+	// a window of n characters has n-1 changes of angle between them
+	window := opts.AngleWindow - 1
+	if opts.MaxWindowAngleDelta > 0 && window > 0 {
+		var total float64
+		for i, d := range deltas {
+			total += math.Abs(d)
+			if i >= window {
+				total -= math.Abs(deltas[i-window])
+			}
+			if total > opts.MaxWindowAngleDelta {
+				return fmt.Errorf("[%v] %w [%v:%v] (%v > %v)", label, ErrTooCurvy, i+1-window, i+1, total, opts.MaxWindowAngleDelta)
+			}
+		}
+	}
+
+	return nil
+}
+
+// the change in angle between each pair of adjacent characters, between -180 and 180 degrees.
+func angleDeltas(letterPositions []LetterPosition) []float64 {
+	var deltas []float64
+
+	for i := 1; i < len(letterPositions); i++ {
+		d := math.Mod(letterPositions[i].Angle-letterPositions[i-1].Angle, 360)
+		if d > 180 {
+			d -= 360
+		} else if d < -180 {
+			d += 360
+		}
+		deltas = append(deltas, d)
+	}
+
+	return deltas
+}
@@ -0,0 +1,177 @@
+package text
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	textdraw "github.com/rockwell-uk/go-draw/draw"
+
+	"github.com/rockwell-uk/go-text/fonts"
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestGetLetterPositionsCurvature(t *testing.T) {
+	circleCoords, err := textdraw.Circle([]float64{200, 200}, 140, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		points   [][]float64
+		label    string
+		opts     LineOptions
+		expected []LetterPosition
+		err      error
+	}{
+		"Corner": {
+			[][]float64{{0, 0}, {100, 0}, {100, 100}, {400, 100}},
+			"Mellor Street",
+			LineOptions{MaxCharAngleDelta: 45},
+			nil,
+			ErrTooCurvy,
+		},
+		"Corner search": {
+			[][]float64{{0, 0}, {100, 0}, {100, 100}, {400, 100}},
+			"Mellor Street",
+			LineOptions{MaxCharAngleDelta: 45, SearchStep: 10},
+			[]LetterPosition{
+				{Char: "M", X: 100, Y: 111.33333333333333, Angle: 0},
+				{Char: "e", X: 132.09, Y: 111.33333333333333, Angle: 0},
+				{Char: "l", X: 151, Y: 111.33333333333333, Angle: 0},
+				{Char: "l", X: 160.45, Y: 111.33333333333333, Angle: 0},
+				{Char: "o", X: 169.89999999999998, Y: 111.33333333333333, Angle: 0},
+				{Char: "r", X: 190.67, Y: 111.33333333333333, Angle: 0},
+				{Char: " ", X: 203.89999999999998, Y: 111.33333333333333, Angle: 0},
+				{Char: "S", X: 213.34999999999997, Y: 111.33333333333333, Angle: 0},
+				{Char: "t", X: 236.01999999999998, Y: 111.33333333333333, Angle: 0},
+				{Char: "r", X: 249.24999999999997, Y: 111.33333333333333, Angle: 0},
+				{Char: "e", X: 262.47999999999996, Y: 111.33333333333333, Angle: 0},
+				{Char: "e", X: 281.39, Y: 111.33333333333333, Angle: 0},
+				{Char: "t", X: 300.3, Y: 111.33333333333333, Angle: 0},
+			},
+			nil,
+		},
+		"Corner search too short": {
+			[][]float64{{0, 0}, {100, 0}, {100, 100}, {150, 100}},
+			"Mellor Street",
+			LineOptions{MaxCharAngleDelta: 45, SearchStep: 10},
+			nil,
+			ErrTooCurvy,
+		},
+		"Gentle corner": {
+			[][]float64{{0, 0}, {100, 0}, {300, 40}},
+			"Mellor Street",
+			LineOptions{MaxCharAngleDelta: 45},
+			[]LetterPosition{
+				{Char: "M", X: 0, Y: 11.333333333333334, Angle: 0},
+				{Char: "e", X: 32.09, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 51, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 60.45, Y: 11.333333333333334, Angle: 0},
+				{Char: "o", X: 69.9, Y: 11.333333333333334, Angle: 0},
+				{Char: "r", X: 90.67, Y: 11.333333333333334, Angle: 0},
+				{Char: " ", X: 101.6016151036285, Y: 11.878100584869346, Angle: 11.309932474020215},
+				{Char: "S", X: 110.8681024889077, Y: 13.731398061925185, Angle: 11.309932474020215},
+				{Char: "t", X: 133.09786640682088, Y: 18.177350845507817, Angle: 11.309932474020215},
+				{Char: "r", X: 146.07094874621177, Y: 20.771967313385993, Angle: 11.309932474020215},
+				{Char: "e", X: 159.04403108560265, Y: 23.36658378126417, Angle: 11.309932474020215},
+				{Char: "e", X: 177.58681166291794, Y: 27.07513989672723, Angle: 11.309932474020215},
+				{Char: "t", X: 196.12959224023322, Y: 30.78369601219029, Angle: 11.309932474020215},
+			},
+			nil,
+		},
+		"Circle": {
+			circleCoords,
+			"Why are there two equation expressions?",
+			LineOptions{Smooth: true, MaxCharAngleDelta: 20},
+			[]LetterPosition{
+				{Char: "W", X: 328.3315738109567, Y: 218.8932221864619, Angle: 106.68231782397793},
+				{Char: "h", X: 319.1723195205626, Y: 249.52421934458803, Angle: 117.19357584443246},
+				{Char: "y", X: 310.32897241620145, Y: 266.59805108136356, Angle: 125.19746860506731},
+				{Char: " ", X: 299.5954022738523, Y: 280.84119351643653, Angle: 130.49999999999991},
+				{Char: "a", X: 294.6615469096987, Y: 287.31411431781135, Angle: 137.2450808488814},
+				{Char: "r", X: 281.69930426013343, Y: 299.45382527929223, Angle: 143.1921640743806},
+				{Char: "e", X: 272.0265009028877, Y: 306.47956884247037, Angle: 149.62276514284218},
+				{Char: " ", X: 257.0331798972979, Y: 315.1767482696247, Angle: 157.49999999999974},
+				{Char: "t", X: 248.7764694432394, Y: 318.8667018675572, Angle: 159.59410105631954},
+				{Char: "h", X: 237.81116905252574, Y: 322.9535865387907, Angle: 167.3111234079816},
+				{Char: "e", X: 219.13579687475982, Y: 327.1256018750352, Angle: 175.50000000000009},
+				{Char: "r", X: 201.91166465223486, Y: 328.69074292344453, Angle: -176.29789678383113},
+				{Char: "e", X: 189.63228071246232, Y: 328.5549447670115, Angle: -171.22054314341221},
+				{Char: " ", X: 171.99887669013046, Y: 325.156443465555, Angle: -166.49999999999983},
+				{Char: "t", X: 163.98988070406804, Y: 323.59641434938453, Angle: -159.89311081444862},
+				{Char: "w", X: 152.66811623663168, Y: 320.2913304294727, Angle: -152.1485620402814},
+				{Char: "o", X: 128.04936507355941, Y: 306.78310033350465, Angle: -141.2305723179412},
+				{Char: " ", X: 112.68370227621577, Y: 294.54119711244397, Angle: -135.4513346650696},
+				{Char: "e", X: 106.8635867142902, Y: 288.5477305249421, Angle: -129.936354873099},
+				{Char: "q", X: 95.77816326884475, Y: 275.3521101267921, Angle: -121.49999999999997},
+				{Char: "u", X: 85.73257116660221, Y: 259.22709183589905, Angle: -112.50291301198382},
+				{Char: "a", X: 78.21832478672633, Y: 241.6563241668518, Angle: -104.07183384444917},
+				{Char: "t", X: 73.62990457961374, Y: 224.65781445758864, Angle: -97.39673402704186},
+				{Char: "i", X: 72.3185521535686, Y: 212.07310416042162, Angle: -94.50000000000004},
+				{Char: "o", X: 71.31723560575786, Y: 204.14555088914298, Angle: -87.0361803116899},
+				{Char: "n", X: 72.08623182384116, Y: 185.04801916048854, Angle: -78.5555493309594},
+				{Char: " ", X: 76.04553968952831, Y: 165.73281286399182, Angle: -73.16653976105545},
+				{Char: "e", X: 78.61486083955, Y: 157.86541332375472, Angle: -67.29219105754485},
+				{Char: "x", X: 85.15327066564682, Y: 141.9861387932986, Angle: -58.499999999999986},
+				{Char: "p", X: 93.95697874031084, Y: 127.02015801680587, Angle: -50.63099672322615},
+				{Char: "r", X: 106.04594980868168, Y: 111.98222360091647, Angle: -43.09124266121621},
+				{Char: "e", X: 114.74653152288101, Y: 103.497075023407, Angle: -37.47867592097827},
+				{Char: "s", X: 128.87119881097996, Y: 93.06160389725551, Angle: -30.767612705676637},
+				{Char: "s", X: 142.06055333944678, Y: 85.19863971484367, Angle: -22.49999999999996},
+				{Char: "i", X: 156.62544110348347, Y: 78.80266575638018, Angle: -16.907007818226298},
+				{Char: "o", X: 164.62141497515705, Y: 76.23132861755553, Angle: -11.481134125057686},
+				{Char: "n", X: 183.3309272022991, Y: 72.40382442708683, Angle: -3.0004715343941197},
+				{Char: "s", X: 202.57223614783945, Y: 71.57081762466296, Angle: 4.500000000000018},
+				{Char: "?", X: 217.92563629703926, Y: 72.54402845249082, Angle: 12.710233973428213},
+			},
+			nil,
+		},
+		"Circle window": {
+			circleCoords,
+			"Why are there two equation expressions?",
+			LineOptions{Smooth: true, MaxCharAngleDelta: 20, MaxWindowAngleDelta: 30, AngleWindow: 5},
+			nil,
+			ErrTooCurvy,
+		},
+	}
+
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 34,
+	}
+	face := truetype.NewFace(f, &opts)
+
+	typeFace := fonts.TypeFace{
+		Size: 34,
+		FontData: draw2d.FontData{
+			Name:   "bold",
+			Family: draw2d.FontFamilySans,
+			Style:  draw2d.FontStyleNormal,
+		},
+		Face: face,
+	}
+
+	for name, tt := range tests {
+		actual, err := GetLetterPositionsWithOptions(tt.label, tt.points, typeFace, tt.opts)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%v: Expected [%v], Got [%v]", name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package text
+
+import (
+	"errors"
+	"math"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+// Fit controls what is done to make a label fit the length of a line.
+type Fit int
+
+const (
+	// tighten the spacing, down to LineOptions.MinSpacing, then reduce the font size,
+	// down to LineOptions.MinSize, until the label fits
+	FitShrink Fit = 1 << iota
+	// spread the letters out so the label fills the line
+	FitJustify
+)
+
+// Adjustments records the changes made to a typeface to fit a label to a line.
+type Adjustments struct {
+	// the typeface the label was laid out with, draw the glyphs with this
+	TypeFace fonts.TypeFace
+	// how much the letter spacing changed by, negative values are tighter
+	Spacing float64
+	// how much the font size changed by
+	Size float64
+}
+
+func (a Adjustments) Adjusted() bool {
+	return a.Spacing != 0 || a.Size != 0
+}
+
+// TextAlongLineFitted places the label along the line like TextAlongLineWithOptions,
+// adjusting the spacing and size of the typeface according to opts.Fit.
+// The glyphs must be drawn with the returned TypeFace.
+func TextAlongLineFitted(gc *draw2dimg.GraphicContext, label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions) ([]TextGlyph, Adjustments, error) {
+	charpositions, adjustments, err := GetLetterPositionsFitted(label, lineCoords, tf, opts)
+	if err != nil {
+		return []TextGlyph{}, adjustments, err
+	}
+
+	return toTextGlyphs(charpositions), adjustments, nil
+}
+
+func GetLetterPositionsFitted(label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions) ([]LetterPosition, Adjustments, error) {
+	lineCoords, opts = prepareLine(lineCoords, opts)
+
+	// only truncate once everything else has been tried
+	truncate := opts.Truncate
+	opts.Truncate = TruncateNone
+
+	letterPositions, err := GetLetterPositionsWithOptions(label, lineCoords, tf, opts)
+	if err == nil {
+		if opts.Fit&FitJustify != 0 {
+			return justify(label, lineCoords, tf, opts, letterPositions)
+		}
+		return letterPositions, Adjustments{TypeFace: tf}, nil
+	}
+
+	if !errors.Is(err, ErrLettersDontFit) {
+		return letterPositions, Adjustments{TypeFace: tf}, err
+	}
+
+	if opts.Fit&FitShrink != 0 {
+		lps, adjustments, serr := shrink(label, lineCoords, tf, opts)
+		if serr == nil {
+			return lps, adjustments, nil
+		}
+		if !errors.Is(serr, ErrLettersDontFit) {
+			return letterPositions, Adjustments{TypeFace: tf}, serr
+		}
+		err = serr
+	}
+
+	if truncate != TruncateNone {
+		opts.Truncate = truncate
+		letterPositions, err = GetLetterPositionsWithOptions(label, lineCoords, tf, opts)
+	}
+
+	return letterPositions, Adjustments{TypeFace: tf}, err
+}
+
+// DefaultSizeStep is how much the font size is reduced by each time when shrinking a label
+// to fit, if LineOptions.SizeStep isnt set.
+const DefaultSizeStep = 1.0
+
+// shrink tightens the spacing, then reduces the font size, until the label fits.
+func shrink(label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions) ([]LetterPosition, Adjustments, error) {
+	var err error
+
+	step := opts.SizeStep
+	if step <= 0 {
+		step = DefaultSizeStep
+	}
+
+	size := tf.Size
+	candidate := tf
+	for {
+		// first try tightening up the letters
+		spacing := math.Max(opts.MinSpacing, fitSpacing(label, lineCoords, candidate, opts))
+		if spacing < candidate.Spacing {
+			tightened := candidate
+			tightened.Spacing = spacing
+
+			lps, lerr := GetLetterPositionsWithOptions(label, lineCoords, tightened, opts)
+			if lerr == nil {
+				return lps, adjustmentsFor(tf, tightened), nil
+			}
+			err = lerr
+		}
+
+		// then try a smaller font, stopping a step short of nothing
+		if size-step < math.Max(opts.MinSize, step) {
+			if err == nil {
+				err = ErrLettersDontFit
+			}
+			return []LetterPosition{}, Adjustments{TypeFace: tf}, err
+		}
+		size -= step
+
+		candidate, err = fonts.ResizeTypeFace(tf, size)
+		if err != nil {
+			return []LetterPosition{}, Adjustments{TypeFace: tf}, err
+		}
+
+		lps, lerr := GetLetterPositionsWithOptions(label, lineCoords, candidate, opts)
+		if lerr == nil {
+			return lps, adjustmentsFor(tf, candidate), nil
+		}
+		err = lerr
+	}
+}
+
+// justify increases the spacing so the label fills the space available on the line.
+func justify(label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions, letterPositions []LetterPosition) ([]LetterPosition, Adjustments, error) {
+	spacing := fitSpacing(label, lineCoords, tf, opts)
+	if opts.MaxSpacing > 0 {
+		spacing = math.Min(spacing, opts.MaxSpacing)
+	}
+
+	if spacing <= tf.Spacing {
+		return letterPositions, Adjustments{TypeFace: tf}, nil
+	}
+
+	justified := tf
+	justified.Spacing = spacing
+
+	lps, err := GetLetterPositionsWithOptions(label, lineCoords, justified, opts)
+	if err != nil {
+		// leave it as it was
+		return letterPositions, Adjustments{TypeFace: tf}, nil
+	}
+
+	return lps, adjustmentsFor(tf, justified), nil
+}
+
+// fitSpacing returns the letter spacing which makes the label exactly fill the space available for it.
+func fitSpacing(label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions) float64 {
+	charMetrics := getCharMetrics(label, tf)
+
+	if opts.Closed {
+		lineCoords = closeRing(lineCoords)
+	}
+
+	available := NewPolyline(offsetLine(lineCoords, baselineOffset(tf, opts))).Length()
+	if opts.Anchor == AnchorOffset && !opts.Closed {
+		available -= opts.Offset
+	}
+
+	// the spacing goes between characters, combining marks dont get any
+	gaps := -1
+	for _, r := range label {
+		if !isCombining(r) {
+			gaps++
+		}
+	}
+	if gaps <= 0 {
+		return tf.Spacing
+	}
+
+	return tf.Spacing + (available-labelWidth(charMetrics))/float64(gaps)
+}
+
+func adjustmentsFor(original, adjusted fonts.TypeFace) Adjustments {
+	return Adjustments{
+		TypeFace: adjusted,
+		Spacing:  adjusted.Spacing - original.Spacing,
+		Size:     adjusted.Size - original.Size,
+	}
+}
@@ -0,0 +1,186 @@
+package text
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+
+	"github.com/rockwell-uk/go-text/fonts"
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestGetLetterPositionsFitted(t *testing.T) {
+	tests := map[string]struct {
+		points  [][]float64
+		spacing float64
+		opts    LineOptions
+		size    float64
+		space   float64
+		err     error
+	}{
+		"Fits": {
+			[][]float64{{0, 0}, {200, 0}},
+			float64(2),
+			LineOptions{Anchor: AnchorCenter, Fit: FitShrink},
+			0,
+			0,
+			nil,
+		},
+		"Tighten": {
+			[][]float64{{0, 0}, {110, 0}},
+			float64(2),
+			LineOptions{Anchor: AnchorCenter, Fit: FitShrink},
+			0,
+			-0.78,
+			nil,
+		},
+		"Tighten limit": {
+			[][]float64{{0, 0}, {110, 0}},
+			float64(2),
+			LineOptions{Anchor: AnchorCenter, Fit: FitShrink, MinSpacing: 1.5, MinSize: 34},
+			0,
+			0,
+			ErrLettersDontFit,
+		},
+		"Shrink": {
+			[][]float64{{0, 0}, {80, 0}},
+			float64(0),
+			LineOptions{Anchor: AnchorCenter, Fit: FitShrink, MinSize: 20, SizeStep: 2},
+			-8,
+			0,
+			nil,
+		},
+		"Shrink default step": {
+			[][]float64{{0, 0}, {80, 0}},
+			float64(0),
+			LineOptions{Anchor: AnchorCenter, Fit: FitShrink},
+			-8,
+			0,
+			nil,
+		},
+		"Shrink limit": {
+			[][]float64{{0, 0}, {80, 0}},
+			float64(0),
+			LineOptions{Anchor: AnchorCenter, Fit: FitShrink, MinSize: 28, SizeStep: 2},
+			0,
+			0,
+			ErrLettersDontFit,
+		},
+		"Not fitting": {
+			[][]float64{{0, 0}, {80, 0}},
+			float64(0),
+			LineOptions{Anchor: AnchorCenter},
+			0,
+			0,
+			ErrLettersDontFit,
+		},
+		"Justify": {
+			[][]float64{{0, 0}, {200, 0}},
+			float64(0),
+			LineOptions{Fit: FitJustify},
+			0,
+			19.22,
+			nil,
+		},
+		"Justify limit": {
+			[][]float64{{0, 0}, {200, 0}},
+			float64(0),
+			LineOptions{Fit: FitJustify, MaxSpacing: 10},
+			0,
+			10,
+			nil,
+		},
+	}
+
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 34,
+	}
+	face := truetype.NewFace(f, &opts)
+
+	for name, tt := range tests {
+		typeFace := fonts.TypeFace{
+			Size: 34,
+			FontData: draw2d.FontData{
+				Name:   "bold",
+				Family: draw2d.FontFamilySans,
+				Style:  draw2d.FontStyleNormal,
+			},
+			Face:    face,
+			Spacing: tt.spacing,
+		}
+
+		lps, adjustments, err := GetLetterPositionsFitted("Mellor", tt.points, typeFace, tt.opts)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%v: Expected [%v], Got [%v]", name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if len(lps) != 6 {
+			t.Errorf("%v: Expected 6 letters, Got %v", name, len(lps))
+		}
+
+		if adjustments.Size != tt.size {
+			t.Errorf("%v: Expected size change [%v], Got [%v]", name, tt.size, adjustments.Size)
+		}
+
+		if math.Round(adjustments.Spacing*100)/100 != tt.space {
+			t.Errorf("%v: Expected spacing change [%v], Got [%v]", name, tt.space, adjustments.Spacing)
+		}
+
+		if adjustments.TypeFace.Size != typeFace.Size+adjustments.Size {
+			t.Errorf("%v: Expected adjusted size [%v], Got [%v]", name, typeFace.Size+adjustments.Size, adjustments.TypeFace.Size)
+		}
+	}
+}
+
+func TestGetLetterPositionsFittedFontCache(t *testing.T) {
+	// as if fonts.UseBundledFonts had never been called
+	global := draw2d.GetGlobalFontCache()
+	draw2d.SetFontCache(fonts.MyFontCache{})
+	defer draw2d.SetFontCache(global)
+
+	registry, err := fonts.NewBundledRegistry()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := registry.Load(draw2d.FontData{Name: "univers-bold"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	typeFace := fonts.TypeFace{
+		Name:     "fitted-font-cache",
+		Size:     34,
+		FontData: draw2d.FontData{Name: "univers-bold"},
+		Face:     truetype.NewFace(f, &truetype.Options{Size: 34}),
+	}
+	line := [][]float64{{0, 0}, {80, 0}}
+	opts := LineOptions{Anchor: AnchorCenter, Fit: FitShrink}
+
+	if _, _, err = GetLetterPositionsFitted("Mellor", line, typeFace, opts); err == nil || errors.Is(err, ErrLettersDontFit) {
+		t.Errorf("Expected an error loading the font, Got [%v]", err)
+	}
+
+	typeFace.FontCache = registry
+	_, adjustments, err := GetLetterPositionsFitted("Mellor", line, typeFace, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adjustments.Size >= 0 {
+		t.Errorf("Expected the label to shrink, Got [%v]", adjustments.Size)
+	}
+}
@@ -0,0 +1,16 @@
+package text
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+func TestMain(m *testing.M) {
+	if _, err := fonts.UseBundledFonts(); err != nil {
+		panic(err)
+	}
+
+	os.Exit(m.Run())
+}
@@ -0,0 +1,100 @@
+package text
+
+import (
+	"fmt"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+// TextAlongLineMultiLine places each line of the label along its own baseline, running parallel
+// to the line and stacked with the first line on top, every line centred on the same point.
+// The whole label is positioned by the anchor in opts as though it were the widest line.
+func TextAlongLineMultiLine(gc *draw2dimg.GraphicContext, lines []string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions) ([][]TextGlyph, error) {
+	labels, err := GetLetterPositionsMultiLine(lines, lineCoords, tf, opts)
+	if err != nil {
+		return [][]TextGlyph{}, err
+	}
+
+	textGlyphs := [][]TextGlyph{}
+
+	for _, charpositions := range labels {
+		textGlyphs = append(textGlyphs, toTextGlyphs(charpositions))
+	}
+
+	return textGlyphs, nil
+}
+
+func GetLetterPositionsMultiLine(lines []string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions) ([][]LetterPosition, error) {
+	labels := [][]LetterPosition{}
+	if len(lines) == 0 {
+		return labels, nil
+	}
+
+	lineCoords, opts = prepareLine(lineCoords, opts)
+	if opts.Closed {
+		lineCoords = closeRing(lineCoords)
+	}
+
+	// the widest line decides where the label goes
+	var widest string
+	var widestMetrics []CharMetric
+	width := -1.0
+	for _, l := range lines {
+		charMetrics := getCharMetrics(l, tf)
+		if w := labelWidth(charMetrics); w > width {
+			widest, widestMetrics, width = l, charMetrics, w
+		}
+	}
+
+	line := NewPolyline(lineCoords)
+	length := line.Length()
+	start := anchorDistance(opts, lineCoords, length, width)
+	if start < 0 && !opts.Closed {
+		fm := fonts.GetFaceMetrics(tf)
+		return labels, fmt.Errorf("[%v] %w at %v [%v:%v] (%v:%v)", widest, ErrLettersDontFit, opts.Anchor, 0, len(widestMetrics), fm.Height, tf.Spacing)
+	}
+	centre := start + width/2
+
+	opts.Anchor = AnchorCenterOffset
+	opts.Offset = centre
+
+	// turn the line round first if need be, so the lines stay in order
+	if opts.KeepUpright {
+		opts.KeepUpright = false
+		lps, err := GetLetterPositionsWithOptions(widest, lineCoords, tf, opts)
+		if err == nil && isUpsideDown(lps, widestMetrics) {
+			lineCoords = line.Reverse().Coords()
+			opts.Offset = length - centre
+		}
+	}
+
+	lineHeight := opts.LineHeight
+	if lineHeight <= 0 {
+		lineHeight = 1
+	}
+	spacing := fonts.GetFaceMetrics(tf).Height * lineHeight
+	offset := baselineOffset(tf, opts)
+	middle := float64(len(lines)-1) / 2
+
+	opts.BaselineOffsetUnit = OffsetPixels
+	for i, l := range lines {
+		lineOpts := opts
+		lineOpts.BaselineOffset = offset + (middle-float64(i))*spacing
+
+		// the offset lines are longer or shorter than the line, so keep the same proportion along them
+		if length > 0 {
+			lineOpts.Offset = opts.Offset * NewPolyline(offsetLine(lineCoords, lineOpts.BaselineOffset)).Length() / length
+		}
+
+		lps, err := GetLetterPositionsWithOptions(l, lineCoords, tf, lineOpts)
+		if err != nil {
+			return [][]LetterPosition{}, err
+		}
+
+		labels = append(labels, lps)
+	}
+
+	return labels, nil
+}
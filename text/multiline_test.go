@@ -0,0 +1,79 @@
+package text
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+func TestGetLetterPositionsMultiLine(t *testing.T) {
+	tests := map[string]struct {
+		points [][]float64
+		opts   LineOptions
+	}{
+		"Left to right": {
+			[][]float64{{0, 200}, {400, 200}},
+			LineOptions{Anchor: AnchorCenter},
+		},
+		"Kept upright": {
+			[][]float64{{400, 200}, {0, 200}},
+			LineOptions{Anchor: AnchorCenter, KeepUpright: true},
+		},
+		"Double spaced": {
+			[][]float64{{0, 200}, {400, 200}},
+			LineOptions{Anchor: AnchorCenter, LineHeight: 2},
+		},
+	}
+
+	typeFace := ringTypeFace(t)
+	fm := fonts.GetFaceMetrics(typeFace)
+	lines := []string{"Pilsworth", "Road"}
+
+	for name, tt := range tests {
+		labels, err := GetLetterPositionsMultiLine(lines, tt.points, typeFace, tt.opts)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if len(labels) != 2 {
+			t.Fatalf("%v: Expected [2] lines, Got [%v]", name, len(labels))
+		}
+
+		lineHeight := tt.opts.LineHeight
+		if lineHeight == 0 {
+			lineHeight = 1
+		}
+
+		// the first line is above the second
+		gap := labels[1][0].Y - labels[0][0].Y
+		if math.Abs(gap-fm.Height*lineHeight) > 1e-6 {
+			t.Errorf("%v: Expected the lines to be [%v] apart, Got [%v]", name, fm.Height*lineHeight, gap)
+		}
+
+		// both lines are centred on the middle of the line
+		for i, lps := range labels {
+			width := labelWidth(getCharMetrics(lines[i], typeFace))
+			if math.Abs(lps[0].X+width/2-200) > 1e-6 {
+				t.Errorf("%v: Expected [%v] to be centred, Got [%v]", name, lines[i], lps[0].X+width/2)
+			}
+
+			for _, lp := range lps {
+				if lp.Angle != 0 {
+					t.Errorf("%v: Expected [%v] to be level, Got [%v]", name, lines[i], lp.Angle)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestGetLetterPositionsMultiLineDontFit(t *testing.T) {
+	typeFace := ringTypeFace(t)
+
+	_, err := GetLetterPositionsMultiLine([]string{"Pilsworth Road", "Bury"}, [][]float64{{0, 100}, {100, 100}}, typeFace, LineOptions{Anchor: AnchorCenter})
+	if !errors.Is(err, ErrLettersDontFit) {
+		t.Errorf("Expected [%v], Got [%v]", ErrLettersDontFit, err)
+	}
+}
@@ -0,0 +1,195 @@
+package text
+
+import (
+	"math"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+// OffsetUnit is the unit LineOptions.BaselineOffset is measured in.
+type OffsetUnit int
+
+const (
+	// pixels, the default
+	OffsetPixels OffsetUnit = iota
+	// a fraction of the font's ascent
+	OffsetAscent
+	// a fraction of the font's x-height
+	OffsetXHeight
+)
+
+// corners sharper than this have their outside cut off rather than mitred.
+const miterLimit = 2.0
+
+// baselineOffset converts the offset in the options to pixels.
+func baselineOffset(tf fonts.TypeFace, opts LineOptions) float64 {
+	if opts.BaselineOffset == 0 {
+		return 0
+	}
+
+	switch opts.BaselineOffsetUnit {
+	case OffsetPixels:
+		return opts.BaselineOffset
+	case OffsetAscent:
+		return opts.BaselineOffset * fonts.GetFaceMetrics(tf).Ascent
+	case OffsetXHeight:
+		xHeight := fonts.GetFaceMetrics(tf).XHeight
+		if xHeight == 0 {
+			// not every face reports its x-height, so measure an x
+			xHeight = fonts.GetGlyphMetrics(tf, 'x').Ascent
+		}
+		return opts.BaselineOffset * xHeight
+	}
+
+	return opts.BaselineOffset
+}
+
+// offsetLine returns the line running parallel to lineCoords, d units to the left
+// of the direction of travel (above the line when travelling left to right).
+// Outside corners are mitred, or bevelled when they are sharp, and the loops
+// that form on the inside of corners are removed.
+func offsetLine(lineCoords [][]float64, d float64) [][]float64 {
+	if d == 0 {
+		return lineCoords
+	}
+
+	points := dedupe(lineCoords)
+	if len(points) < 2 {
+		return points
+	}
+
+	// unit direction and left hand normal of each segment
+	dirs := make([][]float64, len(points)-1)
+	normals := make([][]float64, len(points)-1)
+	for i := 0; i < len(points)-1; i++ {
+		dx := points[i+1][0] - points[i][0]
+		dy := points[i+1][1] - points[i][1]
+		l := math.Hypot(dx, dy)
+		dirs[i] = []float64{dx / l, dy / l}
+		normals[i] = []float64{dy / l, -dx / l}
+	}
+
+	// a closed ring turns the corner at its join like any other
+	closed := len(points) > 3 && points[0][0] == points[len(points)-1][0] && points[0][1] == points[len(points)-1][1]
+
+	first := points[0]
+	n := normals[0]
+	offset := [][]float64{
+		{first[0] + n[0]*d, first[1] + n[1]*d},
+	}
+
+	var join [][]float64
+	if closed {
+		join = offsetCorner(first, normals[len(normals)-1], normals[0], dirs[0], d)
+		offset = [][]float64{join[len(join)-1]}
+	}
+
+	for k := 1; k < len(points)-1; k++ {
+		offset = append(offset, offsetCorner(points[k], normals[k-1], normals[k], dirs[k], d)...)
+	}
+
+	if closed {
+		offset = append(offset, join...)
+	} else {
+		last := points[len(points)-1]
+		n = normals[len(normals)-1]
+		offset = append(offset, []float64{last[0] + n[0]*d, last[1] + n[1]*d})
+	}
+
+	return removeLoops(offset, 2*math.Pi*math.Abs(d))
+}
+
+// offsetCorner returns where the offset line turns the corner at p, from the segment with
+// normal a to the segment with normal b and direction dir. Outside corners are mitred, or
+// bevelled when they are too sharp.
+func offsetCorner(p, a, b, dir []float64, d float64) [][]float64 {
+	cos := a[0]*b[0] + a[1]*b[1]
+	outside := (a[0]*dir[0]+a[1]*dir[1])*d < 0
+
+	// the line doubles back on itself, or the outside corner is too sharp to mitre
+	if cos <= -1+1e-9 || (outside && math.Sqrt(2/(1+cos)) > miterLimit) {
+		return [][]float64{
+			{p[0] + a[0]*d, p[1] + a[1]*d},
+			{p[0] + b[0]*d, p[1] + b[1]*d},
+		}
+	}
+
+	// the mitre point is where the two offset segments meet
+	return [][]float64{{
+		p[0] + (a[0]+b[0])/(1+cos)*d,
+		p[1] + (a[1]+b[1])/(1+cos)*d,
+	}}
+}
+
+// removeLoops cuts out the small loops where a line crosses itself,
+// which happens on the inside of corners when a line is offset.
+// Only loops shorter than maxLength are removed.
+func removeLoops(lineCoords [][]float64, maxLength float64) [][]float64 {
+	result := [][]float64{lineCoords[0]}
+
+	for i := 0; i < len(lineCoords)-1; i++ {
+		p0 := result[len(result)-1]
+		p1 := lineCoords[i+1]
+
+		// look ahead for a later segment that crosses this one
+		travelled := 0.0
+		for j := i + 2; j < len(lineCoords)-1; j++ {
+			travelled += math.Hypot(lineCoords[j][0]-lineCoords[j-1][0], lineCoords[j][1]-lineCoords[j-1][1])
+			if travelled > maxLength {
+				break
+			}
+
+			x, y, ok := intersection(p0, p1, lineCoords[j], lineCoords[j+1])
+			if ok {
+				// carry on from the crossing along segment j
+				p1 = []float64{x, y}
+				i = j - 1
+				break
+			}
+		}
+
+		result = append(result, p1)
+	}
+
+	return result
+}
+
+// intersection returns where the segments a0-a1 and b0-b1 cross, if they do.
+func intersection(a0, a1, b0, b1 []float64) (float64, float64, bool) {
+	rx, ry := a1[0]-a0[0], a1[1]-a0[1]
+	sx, sy := b1[0]-b0[0], b1[1]-b0[1]
+
+	denom := rx*sy - ry*sx
+	if denom == 0 {
+		return 0, 0, false
+	}
+
+	qx, qy := b0[0]-a0[0], b0[1]-a0[1]
+	t := (qx*sy - qy*sx) / denom
+	u := (qx*ry - qy*rx) / denom
+
+	// only count crossings strictly inside both segments
+	const eps = 1e-9
+	if t <= eps || t >= 1-eps || u <= eps || u >= 1-eps {
+		return 0, 0, false
+	}
+
+	return a0[0] + t*rx, a0[1] + t*ry, true
+}
+
+// dedupe removes repeated points, which have no direction.
+func dedupe(lineCoords [][]float64) [][]float64 {
+	var points [][]float64
+
+	for _, c := range lineCoords {
+		if len(points) > 0 {
+			prev := points[len(points)-1]
+			if prev[0] == c[0] && prev[1] == c[1] {
+				continue
+			}
+		}
+		points = append(points, c)
+	}
+
+	return points
+}
@@ -0,0 +1,161 @@
+package text
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+
+	"github.com/rockwell-uk/go-text/fonts"
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestOffsetLine(t *testing.T) {
+	tests := map[string]struct {
+		points   [][]float64
+		offset   float64
+		expected [][]float64
+	}{
+		"Straight": {
+			[][]float64{{0, 0}, {100, 0}},
+			10,
+			[][]float64{{0, -10}, {100, -10}},
+		},
+		"Straight below": {
+			[][]float64{{0, 0}, {100, 0}},
+			-10,
+			[][]float64{{0, 10}, {100, 10}},
+		},
+		"Outside corner": {
+			[][]float64{{0, 0}, {100, 0}, {100, 100}},
+			10,
+			[][]float64{{0, -10}, {110, -10}, {110, 100}},
+		},
+		"Inside corner": {
+			[][]float64{{0, 0}, {100, 0}, {100, 100}},
+			-10,
+			[][]float64{{0, 10}, {90, 10}, {90, 100}},
+		},
+		"Sharp outside corner": {
+			[][]float64{{0, 0}, {100, 0}, {0, 20}},
+			10,
+			[][]float64{{0, -10}, {100, -10}, {101.961, 9.806}, {1.961, 29.806}},
+		},
+		"Inside loop": {
+			[][]float64{{0, 0}, {100, 0}, {105, 5}, {105, 100}},
+			-10,
+			[][]float64{{0, 10}, {95, 10}, {95, 100}},
+		},
+		"Closed ring outside": {
+			[][]float64{{0, 0}, {100, 0}, {100, 100}, {0, 100}, {0, 0}},
+			10,
+			[][]float64{{-10, -10}, {110, -10}, {110, 110}, {-10, 110}, {-10, -10}},
+		},
+		"Closed ring inside": {
+			[][]float64{{0, 0}, {100, 0}, {100, 100}, {0, 100}, {0, 0}},
+			-10,
+			[][]float64{{10, 10}, {90, 10}, {90, 90}, {10, 90}, {10, 10}},
+		},
+		"Closed ring sharp join": {
+			[][]float64{{0, 0}, {100, 0}, {0, 20}, {0, 0}},
+			10,
+			[][]float64{{-10, -10}, {100, -10}, {101.961, 9.806}, {-10, 32.198}, {-10, -10}},
+		},
+		"Repeated point": {
+			[][]float64{{0, 0}, {50, 0}, {50, 0}, {100, 0}},
+			10,
+			[][]float64{{0, -10}, {50, -10}, {100, -10}},
+		},
+	}
+
+	for name, tt := range tests {
+		actual := offsetLine(tt.points, tt.offset)
+
+		// round away floating point noise
+		for _, p := range actual {
+			p[0] = math.Round(p[0]*1000) / 1000
+			p[1] = math.Round(p[1]*1000) / 1000
+		}
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestGetLetterPositionsBaselineOffset(t *testing.T) {
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 34,
+	}
+	face := truetype.NewFace(f, &opts)
+
+	typeFace := fonts.TypeFace{
+		Size: 34,
+		FontData: draw2d.FontData{
+			Name:   "bold",
+			Family: draw2d.FontFamilySans,
+			Style:  draw2d.FontStyleNormal,
+		},
+		Face: face,
+	}
+
+	fm := fonts.GetFaceMetrics(typeFace)
+	xHeight := fonts.GetGlyphMetrics(typeFace, 'x').Ascent
+
+	tests := map[string]struct {
+		points   [][]float64
+		opts     LineOptions
+		expected float64
+	}{
+		"None": {
+			[][]float64{{0, 0}, {500, 0}},
+			LineOptions{},
+			fm.Height / 3,
+		},
+		"Pixels above": {
+			[][]float64{{0, 0}, {500, 0}},
+			LineOptions{BaselineOffset: 20},
+			fm.Height/3 - 20,
+		},
+		"Pixels below": {
+			[][]float64{{0, 0}, {500, 0}},
+			LineOptions{BaselineOffset: -20},
+			fm.Height/3 + 20,
+		},
+		"Ascent": {
+			[][]float64{{0, 0}, {500, 0}},
+			LineOptions{BaselineOffset: 0.5, BaselineOffsetUnit: OffsetAscent},
+			fm.Height/3 - fm.Ascent/2,
+		},
+		"XHeight": {
+			[][]float64{{0, 0}, {500, 0}},
+			LineOptions{BaselineOffset: 1, BaselineOffsetUnit: OffsetXHeight},
+			fm.Height/3 - xHeight,
+		},
+		"Upright stays above": {
+			[][]float64{{500, 0}, {0, 0}},
+			LineOptions{BaselineOffset: 20, KeepUpright: true},
+			fm.Height/3 - 20,
+		},
+	}
+
+	for name, tt := range tests {
+		actual, err := GetLetterPositionsWithOptions("Mellor", tt.points, typeFace, tt.opts)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		for _, lp := range actual {
+			if math.Abs(lp.Y-tt.expected) > 1e-9 {
+				t.Errorf("%v: Expected Y [%v], Got [%v]", name, tt.expected, lp.Y)
+			}
+		}
+	}
+}
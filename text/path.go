@@ -0,0 +1,573 @@
+package text
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/llgcode/draw2d"
+	"github.com/llgcode/draw2d/draw2dimg"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+// the distance along a curve between the points it is sampled at when casting the ray for AnchorAngle.
+const pathStep = 0.5
+
+// ErrSubpaths is returned when a label is placed along a path with more than one subpath.
+var ErrSubpaths = errors.New("the path has more than one subpath")
+
+// Path is a line made up of straight, bezier and arc segments, for placing labels along
+// curves without having to flatten them first. MoveTo starts a new subpath, labels can
+// only be placed along paths with one.
+type Path struct {
+	subpaths []subpath
+	// the current point and the start of the current subpath
+	x, y   float64
+	sx, sy float64
+}
+
+type subpath struct {
+	start    []float64
+	segments []curve
+	closed   bool
+}
+
+// a subpath with the lengths of its segments worked out, so they are only measured once.
+type measuredPath struct {
+	subpath
+	lengths []float64
+	// the distance along the subpath to the start of each segment, and to the end of the last one
+	distances []float64
+}
+
+// a curve is parametrised from t = 0 at its start to t = 1 at its end.
+type curve interface {
+	point(t float64) (float64, float64)
+	derivative(t float64) (float64, float64)
+}
+
+type lineCurve struct {
+	x0, y0, x1, y1 float64
+}
+
+type quadCurve struct {
+	x0, y0, cx, cy, x1, y1 float64
+}
+
+type cubicCurve struct {
+	x0, y0, cx1, cy1, cx2, cy2, x1, y1 float64
+}
+
+type arcCurve struct {
+	cx, cy, rx, ry, startAngle, angle float64
+}
+
+func NewPath() *Path {
+	return &Path{}
+}
+
+// NewPathFromDraw2D converts a draw2d path.
+func NewPathFromDraw2D(p *draw2d.Path) *Path {
+	path := NewPath()
+
+	j := 0
+	for _, cmp := range p.Components {
+		pts := p.Points[j:]
+		switch cmp {
+		case draw2d.MoveToCmp:
+			path.MoveTo(pts[0], pts[1])
+			j += 2
+		case draw2d.LineToCmp:
+			path.LineTo(pts[0], pts[1])
+			j += 2
+		case draw2d.QuadCurveToCmp:
+			path.QuadTo(pts[0], pts[1], pts[2], pts[3])
+			j += 4
+		case draw2d.CubicCurveToCmp:
+			path.CubicTo(pts[0], pts[1], pts[2], pts[3], pts[4], pts[5])
+			j += 6
+		case draw2d.ArcToCmp:
+			path.ArcTo(pts[0], pts[1], pts[2], pts[3], pts[4], pts[5])
+			j += 6
+		case draw2d.CloseCmp:
+			path.Close()
+		}
+	}
+
+	return path
+}
+
+// MoveTo starts a new subpath at x, y.
+func (p *Path) MoveTo(x, y float64) {
+	p.subpaths = append(p.subpaths, subpath{start: []float64{x, y}})
+	p.x, p.y = x, y
+	p.sx, p.sy = x, y
+}
+
+func (p *Path) LineTo(x, y float64) {
+	p.add(&lineCurve{p.x, p.y, x, y}, x, y)
+}
+
+// QuadTo adds a quadratic bezier with the control point cx, cy.
+func (p *Path) QuadTo(cx, cy, x, y float64) {
+	p.add(&quadCurve{p.x, p.y, cx, cy, x, y}, x, y)
+}
+
+// CubicTo adds a cubic bezier with the control points cx1, cy1 and cx2, cy2.
+func (p *Path) CubicTo(cx1, cy1, cx2, cy2, x, y float64) {
+	p.add(&cubicCurve{p.x, p.y, cx1, cy1, cx2, cy2, x, y}, x, y)
+}
+
+// ArcTo adds an elliptical arc around cx, cy from startAngle, sweeping through angle
+// radians, joined to the current point by a straight line like draw2d.
+func (p *Path) ArcTo(cx, cy, rx, ry, startAngle, angle float64) {
+	arc := &arcCurve{cx, cy, rx, ry, startAngle, angle}
+
+	x0, y0 := arc.point(0)
+	if len(p.subpaths) == 0 {
+		p.MoveTo(x0, y0)
+	} else if x0 != p.x || y0 != p.y {
+		p.LineTo(x0, y0)
+	}
+
+	x1, y1 := arc.point(1)
+	p.add(arc, x1, y1)
+}
+
+// Close joins the current subpath back to its start.
+func (p *Path) Close() {
+	if len(p.subpaths) == 0 {
+		return
+	}
+
+	if p.x != p.sx || p.y != p.sy {
+		p.LineTo(p.sx, p.sy)
+	}
+	p.subpaths[len(p.subpaths)-1].closed = true
+}
+
+func (p *Path) add(c curve, x, y float64) {
+	if len(p.subpaths) == 0 {
+		// like draw2d, drawing without moving first starts at the end point
+		p.MoveTo(x, y)
+		return
+	}
+
+	last := &p.subpaths[len(p.subpaths)-1]
+	last.segments = append(last.segments, c)
+	p.x, p.y = x, y
+}
+
+// Length returns the length of the longest subpath.
+func (p *Path) Length() float64 {
+	m, ok := longest(p.measure())
+	if !ok {
+		return 0
+	}
+
+	return m.length()
+}
+
+// Flatten returns points along the longest subpath, no more than step apart along the
+// curves, and whether it is closed. The ends of each segment are always included.
+func (p *Path) Flatten(step float64) ([][]float64, bool) {
+	m, ok := longest(p.measure())
+	if !ok {
+		return [][]float64{}, false
+	}
+
+	return m.flatten(step), m.closed
+}
+
+func (p *Path) measure() []measuredPath {
+	measured := make([]measuredPath, len(p.subpaths))
+	for i, s := range p.subpaths {
+		measured[i] = s.measure()
+	}
+
+	return measured
+}
+
+func longest(measured []measuredPath) (measuredPath, bool) {
+	var longest measuredPath
+	var found bool
+	maxLength := -1.0
+
+	for _, m := range measured {
+		if l := m.length(); l > maxLength {
+			longest = m
+			maxLength = l
+			found = true
+		}
+	}
+
+	return longest, found
+}
+
+func (s subpath) measure() measuredPath {
+	m := measuredPath{
+		subpath:   s,
+		lengths:   make([]float64, len(s.segments)),
+		distances: make([]float64, len(s.segments)+1),
+	}
+
+	for i, c := range s.segments {
+		m.lengths[i] = curveLength(c, 0, 1)
+		m.distances[i+1] = m.distances[i] + m.lengths[i]
+	}
+
+	return m
+}
+
+// close joins the subpath back to its start, if it doesnt finish there already.
+func (s subpath) close() subpath {
+	closed := subpath{start: s.start, segments: s.segments, closed: true}
+	if len(s.segments) == 0 {
+		return closed
+	}
+
+	x, y := s.segments[len(s.segments)-1].point(1)
+	if x != s.start[0] || y != s.start[1] {
+		// dont share the segments with the subpath being closed
+		closed.segments = append(append([]curve{}, s.segments...), &lineCurve{x, y, s.start[0], s.start[1]})
+	}
+
+	return closed
+}
+
+func (m measuredPath) length() float64 {
+	return m.distances[len(m.distances)-1]
+}
+
+func (m measuredPath) flatten(step float64) [][]float64 {
+	points := [][]float64{m.start}
+
+	for i, c := range m.segments {
+		l := m.lengths[i]
+		if l == 0 {
+			continue
+		}
+
+		// straight lines dont need anything in between
+		n := 1
+		if _, ok := c.(*lineCurve); !ok && step > 0 {
+			n = int(math.Ceil(l / step))
+		}
+
+		// sample the curve at equal distances along it rather than equal values of t
+		for j := 1; j <= n; j++ {
+			x, y := c.point(curveAtLength(c, l, l*float64(j)/float64(n)))
+			points = append(points, []float64{x, y})
+		}
+	}
+
+	return points
+}
+
+// at returns the point d along the subpath and the direction of the subpath there as a unit
+// vector. Distances past the ends are clamped to them, or go round again on a closed subpath.
+func (m measuredPath) at(d float64) (float64, float64, float64, float64) {
+	length := m.length()
+	if length == 0 {
+		return m.start[0], m.start[1], 0, 0
+	}
+
+	if m.closed {
+		d = math.Mod(d, length)
+		if d < 0 {
+			d += length
+		}
+	}
+
+	// the first segment finishing at or after d, stepping over any without length
+	i := sort.SearchFloat64s(m.distances[1:], d)
+	if i >= len(m.segments) {
+		i = len(m.segments) - 1
+	}
+	for i < len(m.segments)-1 && m.lengths[i] == 0 {
+		i++
+	}
+	for i > 0 && m.lengths[i] == 0 {
+		i--
+	}
+
+	c := m.segments[i]
+	t := curveAtLength(c, m.lengths[i], d-m.distances[i])
+	x, y := c.point(t)
+
+	dx, dy := c.derivative(t)
+	if l := math.Hypot(dx, dy); l > 0 {
+		return x, y, dx / l, dy / l
+	}
+
+	// a bezier with a control point on its end has no direction there, take it from either side
+	const h = 1e-6
+	x0, y0 := c.point(math.Max(0, t-h))
+	x1, y1 := c.point(math.Min(1, t+h))
+	if l := math.Hypot(x1-x0, y1-y0); l > 0 {
+		return x, y, (x1 - x0) / l, (y1 - y0) / l
+	}
+
+	return x, y, 0, 0
+}
+
+// TextAlongPath places the label along the path, which must only have one subpath. Each glyph
+// is centred on its distance along the exact curve, turned to the direction of the curve
+// there, and a closed path is treated as a ring.
+func TextAlongPath(gc *draw2dimg.GraphicContext, label string, path *Path, tf fonts.TypeFace, opts LineOptions) ([]TextGlyph, error) {
+	charpositions, err := GetLetterPositionsAlongPath(label, path, tf, opts)
+	if err != nil {
+		return []TextGlyph{}, err
+	}
+
+	return toTextGlyphs(charpositions), nil
+}
+
+// GetLetterPositionsAlongPath places the label along the path like GetLetterPositionsWithOptions
+// with Smooth set, measuring the distances along the curves rather than a flattened line. A
+// baseline offset moves the glyphs to the side of the path without changing where along it they
+// are. The path isnt simplified, smoothed or truncated, whatever the options say.
+func GetLetterPositionsAlongPath(label string, path *Path, tf fonts.TypeFace, opts LineOptions) ([]LetterPosition, error) {
+	measured := path.measure()
+
+	var subpaths int
+	for _, m := range measured {
+		if m.length() > 0 {
+			subpaths++
+		}
+	}
+	if subpaths > 1 {
+		return []LetterPosition{}, fmt.Errorf("[%v] %w (%v)", label, ErrSubpaths, subpaths)
+	}
+
+	line, _ := longest(measured)
+	if opts.Closed && !line.closed {
+		line = line.close().measure()
+	}
+	opts.Closed = line.closed
+
+	charMetrics := getCharMetrics(label, tf)
+	width := labelWidth(charMetrics)
+	length := line.length()
+
+	// work out how far along the path the label starts
+	var lineCoords [][]float64
+	if opts.Anchor == AnchorAngle && len(line.segments) > 0 {
+		lineCoords = line.flatten(pathStep)
+	}
+	start := anchorDistance(opts, lineCoords, length, width)
+
+	if opts.Closed {
+		if width > length {
+			fm := fonts.GetFaceMetrics(tf)
+			return []LetterPosition{},
+				fmt.Errorf("[%v] %w [%v:%v] (%v:%v)", label, ErrLettersDontFit, 0, len(charMetrics), fm.Height, tf.Spacing)
+		}
+
+		start = math.Mod(start, length)
+		if start < 0 {
+			start += length
+		}
+	}
+
+	// if the path is too curvy here, try further along
+	for searched := 0.0; ; searched += opts.SearchStep {
+		letterPositions, err := placeUprightAlongPath(label, charMetrics, line, start+searched, width, tf, opts)
+		if err == nil {
+			err = checkCurvature(label, letterPositions, opts)
+		}
+
+		if !errors.Is(err, ErrTooCurvy) || opts.SearchStep <= 0 {
+			return letterPositions, err
+		}
+
+		// a ring has been searched all the way round once it is back where it started
+		next := searched + opts.SearchStep
+		if (opts.Closed && next >= length) || (!opts.Closed && start+next+width > length) {
+			return letterPositions, err
+		}
+	}
+}
+
+// placeUprightAlongPath places the label start along the path, running it the other way along
+// the path when KeepUpright is set and it would be upside down.
+func placeUprightAlongPath(label string, charMetrics []CharMetric, line measuredPath, start, width float64, tf fonts.TypeFace, opts LineOptions) ([]LetterPosition, error) {
+	letterPositions, err := placeAlongPath(label, charMetrics, line, start, false, tf, opts)
+	if !opts.KeepUpright || !isUpsideDown(letterPositions, charMetrics) {
+		return letterPositions, err
+	}
+
+	// cover the same stretch of the path going the other way
+	reversed, rerr := placeAlongPath(label, charMetrics, line, line.length()-start-width, true, tf, opts)
+	if rerr != nil && err == nil {
+		return letterPositions, nil
+	}
+
+	return reversed, rerr
+}
+
+// placeAlongPath places each character by the centre of its advance at its distance along the
+// path, turned to the direction of the path there. reversed measures the distances from the end
+// of the path, running the label back towards its start.
+func placeAlongPath(label string, charMetrics []CharMetric, line measuredPath, start float64, reversed bool, tf fonts.TypeFace, opts LineOptions) ([]LetterPosition, error) {
+	fm := fonts.GetFaceMetrics(tf)
+
+	if start < 0 && !line.closed {
+		return []LetterPosition{},
+			fmt.Errorf("[%v] %w at %v [%v:%v] (%v:%v)", label, ErrLettersDontFit, opts.Anchor, 0, len(charMetrics), fm.Height, tf.Spacing)
+	}
+
+	offset := baselineOffset(tf, opts)
+	length := line.length()
+
+	var letterPositions []LetterPosition
+
+	d := start
+	for _, charMetric := range charMetrics {
+		half := charMetric.Metrics.Advance / 2
+		centre := d + half
+
+		// the centre of the character has to be on the path
+		if centre > length && !line.closed {
+			break
+		}
+
+		var x, y, dx, dy float64
+		if reversed {
+			x, y, dx, dy = line.at(length - centre)
+			dx, dy = -dx, -dy
+		} else {
+			x, y, dx, dy = line.at(centre)
+		}
+
+		// to the left of the direction of travel like offsetLine
+		cx, cy := x+dy*offset, y-dx*offset
+
+		// step back from the centre to where the character starts, then
+		// offset it so the character is centred on the path
+		letterPositions = append(letterPositions, LetterPosition{
+			Char:  charMetric.Char,
+			X:     cx - dx*half - dy*fm.Height/3,
+			Y:     cy - dy*half + dx*fm.Height/3,
+			Angle: math.Atan2(dy, dx) / (math.Pi / 180),
+		})
+
+		d += charMetric.Width
+	}
+
+	if len(letterPositions) < len(charMetrics) {
+		return letterPositions,
+			fmt.Errorf("[%v] %w [%v:%v] (%v:%v)", label, ErrLettersDontFit, len(letterPositions), len(charMetrics), fm.Height, tf.Spacing)
+	}
+
+	return letterPositions, nil
+}
+
+func (c *lineCurve) point(t float64) (float64, float64) {
+	return c.x0 + (c.x1-c.x0)*t, c.y0 + (c.y1-c.y0)*t
+}
+
+func (c *lineCurve) derivative(t float64) (float64, float64) {
+	return c.x1 - c.x0, c.y1 - c.y0
+}
+
+func (c *quadCurve) point(t float64) (float64, float64) {
+	u := 1 - t
+	return u*u*c.x0 + 2*u*t*c.cx + t*t*c.x1,
+		u*u*c.y0 + 2*u*t*c.cy + t*t*c.y1
+}
+
+func (c *quadCurve) derivative(t float64) (float64, float64) {
+	u := 1 - t
+	return 2*u*(c.cx-c.x0) + 2*t*(c.x1-c.cx),
+		2*u*(c.cy-c.y0) + 2*t*(c.y1-c.cy)
+}
+
+func (c *cubicCurve) point(t float64) (float64, float64) {
+	u := 1 - t
+	return u*u*u*c.x0 + 3*u*u*t*c.cx1 + 3*u*t*t*c.cx2 + t*t*t*c.x1,
+		u*u*u*c.y0 + 3*u*u*t*c.cy1 + 3*u*t*t*c.cy2 + t*t*t*c.y1
+}
+
+func (c *cubicCurve) derivative(t float64) (float64, float64) {
+	u := 1 - t
+	return 3*u*u*(c.cx1-c.x0) + 6*u*t*(c.cx2-c.cx1) + 3*t*t*(c.x1-c.cx2),
+		3*u*u*(c.cy1-c.y0) + 6*u*t*(c.cy2-c.cy1) + 3*t*t*(c.y1-c.cy2)
+}
+
+func (c *arcCurve) point(t float64) (float64, float64) {
+	a := c.startAngle + c.angle*t
+	return c.cx + math.Cos(a)*c.rx, c.cy + math.Sin(a)*c.ry
+}
+
+func (c *arcCurve) derivative(t float64) (float64, float64) {
+	a := c.startAngle + c.angle*t
+	return -math.Sin(a) * c.rx * c.angle, math.Cos(a) * c.ry * c.angle
+}
+
+// 5 point gauss-legendre quadrature.
+var (
+	gaussNodes   = []float64{0, -0.5384693101056831, 0.5384693101056831, -0.9061798459386640, 0.9061798459386640}
+	gaussWeights = []float64{0.5688888888888889, 0.4786286704993665, 0.4786286704993665, 0.2369268850561891, 0.2369268850561891}
+)
+
+// the number of pieces a curve is split into when measuring it.
+const gaussPieces = 16
+
+// curveLength measures the length of the curve between t0 and t1 by integrating its speed.
+func curveLength(c curve, t0, t1 float64) float64 {
+	var l float64
+
+	h := (t1 - t0) / gaussPieces
+	for p := 0; p < gaussPieces; p++ {
+		mid := t0 + h*(float64(p)+0.5)
+		for i, n := range gaussNodes {
+			dx, dy := c.derivative(mid + n*h/2)
+			l += gaussWeights[i] * math.Hypot(dx, dy)
+		}
+	}
+
+	return l * math.Abs(h) / 2
+}
+
+// curveAtLength returns the value of t that is d along a curve of length l.
+func curveAtLength(c curve, l, d float64) float64 {
+	if d <= 0 {
+		return 0
+	}
+	if d >= l {
+		return 1
+	}
+
+	lo, hi := 0.0, 1.0
+	t := d / l
+
+	// newton's method, falling back to bisection when it wanders off
+	for i := 0; i < 32; i++ {
+		f := curveLength(c, 0, t) - d
+		if math.Abs(f) < 1e-9 {
+			break
+		}
+
+		if f > 0 {
+			hi = t
+		} else {
+			lo = t
+		}
+
+		dx, dy := c.derivative(t)
+		speed := math.Hypot(dx, dy)
+
+		next := (lo + hi) / 2
+		if speed > 0 {
+			if n := t - f/speed; n > lo && n < hi {
+				next = n
+			}
+		}
+		t = next
+	}
+
+	return t
+}
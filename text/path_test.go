@@ -0,0 +1,269 @@
+package text
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/llgcode/draw2d"
+)
+
+func TestPathLength(t *testing.T) {
+	tests := map[string]struct {
+		path     func() *Path
+		expected float64
+	}{
+		"Line": {
+			func() *Path {
+				p := NewPath()
+				p.MoveTo(0, 0)
+				p.LineTo(30, 40)
+				return p
+			},
+			50,
+		},
+		"Straight quad": {
+			func() *Path {
+				p := NewPath()
+				p.MoveTo(0, 0)
+				p.QuadTo(50, 0, 100, 0)
+				return p
+			},
+			100,
+		},
+		"Straight cubic": {
+			func() *Path {
+				p := NewPath()
+				p.MoveTo(0, 0)
+				p.CubicTo(10, 0, 90, 0, 100, 0)
+				return p
+			},
+			100,
+		},
+		"Quarter circle": {
+			func() *Path {
+				p := NewPath()
+				p.ArcTo(0, 0, 100, 100, 0, math.Pi/2)
+				return p
+			},
+			50 * math.Pi,
+		},
+		"Line to an arc": {
+			func() *Path {
+				p := NewPath()
+				p.MoveTo(0, 0)
+				p.ArcTo(200, 0, 100, 100, math.Pi, math.Pi)
+				return p
+			},
+			100 + 100*math.Pi,
+		},
+		"Closed": {
+			func() *Path {
+				p := NewPath()
+				p.MoveTo(0, 0)
+				p.LineTo(100, 0)
+				p.LineTo(100, 100)
+				p.LineTo(0, 100)
+				p.Close()
+				return p
+			},
+			400,
+		},
+		"Longest subpath": {
+			func() *Path {
+				p := NewPath()
+				p.MoveTo(0, 0)
+				p.LineTo(10, 0)
+				p.MoveTo(0, 10)
+				p.LineTo(20, 10)
+				return p
+			},
+			20,
+		},
+		"Empty": {
+			NewPath,
+			0,
+		},
+	}
+
+	for name, tt := range tests {
+		actual := tt.path().Length()
+
+		if math.Abs(tt.expected-actual) > 1e-9 {
+			t.Errorf("%v: Expected [%v], Got [%v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestPathFlatten(t *testing.T) {
+	p := NewPath()
+	p.MoveTo(0, 0)
+	p.CubicTo(0, 100, 100, 100, 100, 0)
+
+	points, closed := p.Flatten(2)
+	if closed {
+		t.Errorf("Expected an open path")
+	}
+
+	// the points are spread evenly along the curve, not bunched up where it is tight
+	var shortest, longest float64
+	shortest = math.Inf(1)
+	for i := 1; i < len(points); i++ {
+		d := math.Hypot(points[i][0]-points[i-1][0], points[i][1]-points[i-1][1])
+		shortest = math.Min(shortest, d)
+		longest = math.Max(longest, d)
+	}
+
+	if longest > 2 || longest-shortest > 0.01 {
+		t.Errorf("Expected evenly spaced points no more than 2 apart, Got [%v:%v]", shortest, longest)
+	}
+
+	last := points[len(points)-1]
+	if math.Abs(last[0]-100) > 1e-9 || math.Abs(last[1]) > 1e-9 {
+		t.Errorf("Expected the path to finish at [100 0], Got [%v]", last)
+	}
+}
+
+func TestNewPathFromDraw2D(t *testing.T) {
+	d := new(draw2d.Path)
+	d.MoveTo(0, 0)
+	d.LineTo(50, 0)
+	d.QuadCurveTo(75, 0, 75, 25)
+	d.CubicCurveTo(75, 50, 50, 75, 25, 75)
+	d.ArcTo(0, 75, 25, 25, 0, math.Pi)
+	d.Close()
+
+	p := NewPath()
+	p.MoveTo(0, 0)
+	p.LineTo(50, 0)
+	p.QuadTo(75, 0, 75, 25)
+	p.CubicTo(75, 50, 50, 75, 25, 75)
+	p.ArcTo(0, 75, 25, 25, 0, math.Pi)
+	p.Close()
+
+	expected, expectedClosed := p.Flatten(1)
+	actual, actualClosed := NewPathFromDraw2D(d).Flatten(1)
+
+	if !reflect.DeepEqual(expected, actual) || expectedClosed != actualClosed {
+		t.Errorf("Expected [%+v]\nActual [%+v]", expected, actual)
+	}
+}
+
+func TestGetLetterPositionsAlongPath(t *testing.T) {
+	// the top half of a circle, from left to right
+	p := NewPath()
+	p.ArcTo(0, 0, 200, 200, math.Pi, math.Pi)
+
+	typeFace := ringTypeFace(t)
+
+	lps, err := GetLetterPositionsAlongPath("MOM", p, typeFace, LineOptions{Anchor: AnchorCenter})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(lps[1].Angle) > 0.1 {
+		t.Errorf("Expected the middle letter to be level, Got [%v]", lps[1].Angle)
+	}
+
+	if math.Abs(lps[0].Angle+lps[2].Angle) > 0.1 {
+		t.Errorf("Expected the outer letters to be symmetrical, Got [%v] [%v]", lps[0].Angle, lps[2].Angle)
+	}
+
+	// each letter is turned to the direction of the circle at the middle of its baseline
+	charMetrics := getCharMetrics("MOM", typeFace)
+	for i, lp := range lps {
+		half := charMetrics[i].Metrics.Advance / 2
+		radians := lp.Angle * (math.Pi / 180)
+		mx := lp.X + math.Cos(radians)*half
+		my := lp.Y + math.Sin(radians)*half
+
+		if tangent := math.Atan2(mx, -my) / (math.Pi / 180); math.Abs(tangent-lp.Angle) > 1e-6 {
+			t.Errorf("Expected %q to be at [%v], Got [%v]", lp.Char, tangent, lp.Angle)
+		}
+	}
+
+	_, err = GetLetterPositionsAlongPath("Pilsworth Road Pilsworth Road Pilsworth Road", p, typeFace, LineOptions{})
+	if err == nil {
+		t.Errorf("Expected the label not to fit")
+	}
+
+	p.MoveTo(0, 100)
+	p.LineTo(400, 100)
+
+	_, err = GetLetterPositionsAlongPath("MOM", p, typeFace, LineOptions{})
+	if !errors.Is(err, ErrSubpaths) {
+		t.Errorf("Expected [%v], Got [%v]", ErrSubpaths, err)
+	}
+}
+
+func TestGetLetterPositionsAlongPathSpacing(t *testing.T) {
+	tests := map[string]struct {
+		label   string
+		spacing float64
+	}{
+		"No spacing": {
+			"Mellor",
+			0,
+		},
+		"Spacing": {
+			"Mellor",
+			6,
+		},
+		"Kerning": {
+			"AVATAR WAVE",
+			0,
+		},
+		"Kerning and spacing": {
+			"AVATAR WAVE",
+			3,
+		},
+	}
+
+	for name, tt := range tests {
+		typeFace := ringTypeFace(t)
+		typeFace.Spacing = tt.spacing
+
+		charMetrics := getCharMetrics(tt.label, typeFace)
+		opts := LineOptions{Anchor: AnchorCenter}
+
+		// along a straight path the glyphs go where they do along the same line
+		straight := NewPath()
+		straight.MoveTo(0, 0)
+		straight.LineTo(400, 0)
+
+		actual, err := GetLetterPositionsAlongPath(tt.label, straight, typeFace, opts)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		expected, err := GetLetterPositionsWithOptions(tt.label, [][]float64{{0, 0}, {400, 0}}, typeFace, LineOptions{Anchor: AnchorCenter, Smooth: true})
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		for i := range expected {
+			if expected[i].Char != actual[i].Char || math.Abs(expected[i].X-actual[i].X) > 1e-9 || math.Abs(expected[i].Y-actual[i].Y) > 1e-9 {
+				t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, expected[i], actual[i])
+			}
+		}
+
+		// round the top half of a circle the middles of the first and last glyphs are as far
+		// apart along it as the glyphs before the last one are wide, give or take half of each
+		arc := NewPath()
+		arc.ArcTo(0, 0, 200, 200, math.Pi, math.Pi)
+
+		lps, err := GetLetterPositionsAlongPath(tt.label, arc, typeFace, opts)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		last := len(charMetrics) - 1
+		expectedDistance := labelWidth(charMetrics[:last]) - charMetrics[0].Metrics.Advance/2 + charMetrics[last].Metrics.Advance/2
+		actualDistance := 200 * (lps[last].Angle - lps[0].Angle) * (math.Pi / 180)
+
+		if math.Abs(expectedDistance-actualDistance) > 1e-6 {
+			t.Errorf("%v: Expected [%v], Got [%v]", name, expectedDistance, actualDistance)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package text
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+// ErrTextDontFitPolygon is returned when a label is too big for the polygon it is being placed in.
+var ErrTextDontFitPolygon = errors.New("the text doesnt fit in the polygon")
+
+// PolygonOptions controls how a label is laid out inside a polygon.
+type PolygonOptions struct {
+	// the most lines the label can be wrapped onto, defaults to 3
+	MaxLines int
+	// how the lines line up with each other
+	Align Align
+	// the distance between baselines, as a multiple of the font height, defaults to 1
+	LineHeight float64
+	// space to leave between the label and the edge of the polygon
+	Padding float64
+	// how close to the true pole of inaccessibility to search, defaults to 1
+	Precision float64
+}
+
+// TextInPolygon lays the label out inside the polygon, centred on the point furthest from
+// its edges and wrapped onto however many lines gives it the most room. The first ring of the
+// polygon is its outside, any others are holes in it.
+func TextInPolygon(gc *draw2dimg.GraphicContext, label string, polygon [][][]float64, tf fonts.TypeFace, opts PolygonOptions) ([]TextGlyph, Block, error) {
+	block, err := GetLetterPositionsInPolygon(label, polygon, tf, opts)
+	if err != nil {
+		return []TextGlyph{}, block, err
+	}
+
+	return toTextGlyphs(block.LetterPositions), block, nil
+}
+
+func GetLetterPositionsInPolygon(label string, polygon [][][]float64, tf fonts.TypeFace, opts PolygonOptions) (Block, error) {
+	if len(polygon) == 0 || len(polygon[0]) < 3 {
+		return Block{}, fmt.Errorf("[%v] %w (not a polygon)", label, ErrTextDontFitPolygon)
+	}
+
+	maxLines := opts.MaxLines
+	if maxLines <= 0 {
+		maxLines = 3
+	}
+	precision := opts.Precision
+	if precision <= 0 {
+		precision = 1
+	}
+
+	fm := fonts.GetFaceMetrics(tf)
+	lineHeight := opts.LineHeight
+	if lineHeight <= 0 {
+		lineHeight = 1
+	}
+	lineHeight *= fm.Height
+
+	cx, cy, _ := PoleOfInaccessibility(polygon, precision)
+
+	var best Box
+	var bestLines []string
+	bestRoom := 0.0
+
+	for n := 1; n <= maxLines; n++ {
+		lines := Wrap(label, tf, WrapOptions{Lines: n})
+		if len(lines) < n {
+			// run out of words
+			break
+		}
+
+		var width float64
+		for _, l := range lines {
+			width = math.Max(width, measure(l, tf))
+		}
+		width += 2 * opts.Padding
+		height := float64(n-1)*lineHeight + fm.Ascent + fm.Descent + 2*opts.Padding
+
+		// how much bigger than the label the space for it is
+		rect := InscribedRectangle(polygon, cx, cy, width/height)
+		if room := rect.Width / width; room >= 1 && room > bestRoom {
+			best = Box{
+				X:      rect.X + (rect.Width-width)/2,
+				Y:      rect.Y + (rect.Height-height)/2,
+				Width:  width,
+				Height: height,
+			}
+			bestLines = lines
+			bestRoom = room
+		}
+	}
+
+	if bestLines == nil {
+		return Block{}, fmt.Errorf("[%v] %w (%v:%v)", label, ErrTextDontFitPolygon, fm.Height, tf.Spacing)
+	}
+
+	// leave a little room so the lines arent wrapped again or left off
+	best.X -= 0.5
+	best.Y -= 0.5
+	best.Width++
+	best.Height++
+
+	return GetLetterPositionsInBox(strings.Join(bestLines, "\n"), best, tf, BlockOptions{
+		Align:         opts.Align,
+		VerticalAlign: AlignMiddle,
+		LineHeight:    opts.LineHeight,
+		Padding:       opts.Padding,
+	})
+}
+
+// PoleOfInaccessibility finds the point inside the polygon furthest from its edges, to within
+// precision, returning it and its distance from the nearest edge.
+// https://github.com/mapbox/polylabel
+func PoleOfInaccessibility(polygon [][][]float64, precision float64) (float64, float64, float64) {
+	minX, minY, maxX, maxY := bounds(polygon[0])
+
+	width := maxX - minX
+	height := maxY - minY
+	cellSize := math.Min(width, height)
+	if cellSize == 0 {
+		return minX, minY, 0
+	}
+
+	// cover the polygon with cells
+	cells := &cellQueue{}
+	h := cellSize / 2
+	for x := minX; x < maxX; x += cellSize {
+		for y := minY; y < maxY; y += cellSize {
+			cells.push(newCell(x+h, y+h, h, polygon))
+		}
+	}
+
+	// start with the centroid, or the middle of the bounding box if that is better
+	cx, cy := centroid(polygon[0])
+	best := newCell(cx, cy, 0, polygon)
+	if middle := newCell(minX+width/2, minY+height/2, 0, polygon); middle.d > best.d {
+		best = middle
+	}
+
+	for cells.Len() > 0 {
+		c := cells.pop()
+
+		if c.d > best.d {
+			best = c
+		}
+
+		// there cant be anything better in this cell
+		if c.max-best.d <= precision {
+			continue
+		}
+
+		h = c.h / 2
+		cells.push(newCell(c.x-h, c.y-h, h, polygon))
+		cells.push(newCell(c.x+h, c.y-h, h, polygon))
+		cells.push(newCell(c.x-h, c.y+h, h, polygon))
+		cells.push(newCell(c.x+h, c.y+h, h, polygon))
+	}
+
+	return best.x, best.y, best.d
+}
+
+// InscribedRectangle returns the largest rectangle with the given width to height ratio,
+// centred on x, y, that fits inside the polygon.
+func InscribedRectangle(polygon [][][]float64, x, y, aspect float64) Box {
+	rect := func(height float64) Box {
+		return Box{
+			X:      x - height*aspect/2,
+			Y:      y - height/2,
+			Width:  height * aspect,
+			Height: height,
+		}
+	}
+
+	if aspect <= 0 || !insidePolygon(polygon, x, y) {
+		return rect(0)
+	}
+
+	minX, minY, maxX, maxY := bounds(polygon[0])
+	lo, hi := 0.0, math.Max(maxY-minY, (maxX-minX)/aspect)*2
+
+	// a smaller rectangle fits wherever a bigger one does
+	for i := 0; i < 50; i++ {
+		mid := (lo + hi) / 2
+		if rectangleInside(polygon, rect(mid)) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+
+	return rect(lo)
+}
+
+type cell struct {
+	x, y float64
+	// half the size of the cell
+	h float64
+	// distance from the centre of the cell to the polygon, and the most any point in the cell could be
+	d, max float64
+}
+
+func newCell(x, y, h float64, polygon [][][]float64) cell {
+	d := polygonDistance(polygon, x, y)
+
+	return cell{x: x, y: y, h: h, d: d, max: d + h*math.Sqrt2}
+}
+
+// cellQueue is a heap of cells, the cell that could hold the furthest point first.
+type cellQueue []cell
+
+func (q cellQueue) Len() int           { return len(q) }
+func (q cellQueue) Less(i, j int) bool { return q[i].max > q[j].max }
+func (q cellQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *cellQueue) Push(x any) {
+	if c, ok := x.(cell); ok {
+		*q = append(*q, c)
+	}
+}
+
+func (q *cellQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return c
+}
+
+// push adds the cell to the heap.
+func (q *cellQueue) push(c cell) {
+	heap.Push(q, c)
+}
+
+// pop takes the cell that could hold the furthest point off the heap.
+func (q *cellQueue) pop() cell {
+	c, _ := heap.Pop(q).(cell)
+
+	return c
+}
+
+// polygonDistance returns the distance from x, y to the nearest edge of the polygon, negative outside it.
+func polygonDistance(polygon [][][]float64, x, y float64) float64 {
+	d := math.Inf(1)
+	p := []float64{x, y}
+
+	for _, ring := range polygon {
+		for i := range ring {
+			d = math.Min(d, distanceToSegment(p, ring[i], ring[(i+1)%len(ring)]))
+		}
+	}
+
+	if !insidePolygon(polygon, x, y) {
+		return -d
+	}
+
+	return d
+}
+
+// insidePolygon reports whether x, y is inside the polygon and not in any of its holes.
+func insidePolygon(polygon [][][]float64, x, y float64) bool {
+	var inside bool
+
+	for _, ring := range polygon {
+		for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+			a, b := ring[i], ring[j]
+			if (a[1] > y) != (b[1] > y) && x < (b[0]-a[0])*(y-a[1])/(b[1]-a[1])+a[0] {
+				inside = !inside
+			}
+		}
+	}
+
+	return inside
+}
+
+// rectangleInside reports whether the rectangle is inside the polygon without any edge crossing it.
+func rectangleInside(polygon [][][]float64, r Box) bool {
+	corners := [][]float64{{r.X, r.Y}, {r.X + r.Width, r.Y}, {r.X + r.Width, r.Y + r.Height}, {r.X, r.Y + r.Height}}
+	for _, c := range corners {
+		if !insidePolygon(polygon, c[0], c[1]) {
+			return false
+		}
+	}
+
+	for _, ring := range polygon {
+		for i := range ring {
+			if segmentCrossesBox(ring[i], ring[(i+1)%len(ring)], r) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// segmentCrossesBox reports whether any of the segment a, b is inside the box, by clipping it.
+func segmentCrossesBox(a, b []float64, r Box) bool {
+	t0, t1 := 0.0, 1.0
+	dx, dy := b[0]-a[0], b[1]-a[1]
+
+	clip := func(p, q float64) bool {
+		if p == 0 {
+			return q > 0
+		}
+		t := q / p
+		if p < 0 {
+			if t > t1 {
+				return false
+			}
+			t0 = math.Max(t0, t)
+		} else {
+			if t < t0 {
+				return false
+			}
+			t1 = math.Min(t1, t)
+		}
+		return true
+	}
+
+	return clip(-dx, a[0]-r.X) &&
+		clip(dx, r.X+r.Width-a[0]) &&
+		clip(-dy, a[1]-r.Y) &&
+		clip(dy, r.Y+r.Height-a[1]) &&
+		t0 < t1
+}
+
+func bounds(ring [][]float64) (float64, float64, float64, float64) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+
+	for _, p := range ring {
+		minX = math.Min(minX, p[0])
+		minY = math.Min(minY, p[1])
+		maxX = math.Max(maxX, p[0])
+		maxY = math.Max(maxY, p[1])
+	}
+
+	return minX, minY, maxX, maxY
+}
@@ -0,0 +1,152 @@
+package text
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+var (
+	squarePolygon = [][][]float64{{{0, 0}, {400, 0}, {400, 400}, {0, 400}}}
+	holedPolygon  = [][][]float64{{{0, 0}, {400, 0}, {400, 400}, {0, 400}}, {{100, 100}, {300, 100}, {300, 300}, {100, 300}}}
+)
+
+func TestPoleOfInaccessibility(t *testing.T) {
+	tests := map[string]struct {
+		polygon  [][][]float64
+		distance float64
+	}{
+		"Square": {
+			squarePolygon,
+			200,
+		},
+		"Holed": {
+			holedPolygon,
+			58.58,
+		},
+		"L shape": {
+			[][][]float64{{{0, 0}, {100, 0}, {100, 300}, {400, 300}, {400, 400}, {0, 400}}},
+			58.58,
+		},
+	}
+
+	for name, tt := range tests {
+		x, y, d := PoleOfInaccessibility(tt.polygon, 0.1)
+
+		if math.Abs(d-tt.distance) > 0.1 {
+			t.Errorf("%v: Expected distance [%v], Got [%v] at [%v %v]", name, tt.distance, d, x, y)
+		}
+
+		if math.Abs(polygonDistance(tt.polygon, x, y)-d) > 1e-9 {
+			t.Errorf("%v: Expected [%v %v] to be [%v] from the edge", name, x, y, d)
+		}
+	}
+}
+
+func TestInscribedRectangle(t *testing.T) {
+	tests := map[string]struct {
+		polygon  [][][]float64
+		x, y     float64
+		aspect   float64
+		expected Box
+	}{
+		"Wide": {
+			squarePolygon,
+			200, 200,
+			2,
+			Box{0, 100, 400, 200},
+		},
+		"Off centre": {
+			squarePolygon,
+			100, 200,
+			1,
+			Box{0, 100, 200, 200},
+		},
+		"Holed": {
+			holedPolygon,
+			50, 200,
+			0.25,
+			Box{0, 0, 100, 400},
+		},
+		"Outside": {
+			holedPolygon,
+			200, 200,
+			1,
+			Box{200, 200, 0, 0},
+		},
+	}
+
+	for name, tt := range tests {
+		actual := InscribedRectangle(tt.polygon, tt.x, tt.y, tt.aspect)
+
+		a := []float64{actual.X, actual.Y, actual.Width, actual.Height}
+		e := []float64{tt.expected.X, tt.expected.Y, tt.expected.Width, tt.expected.Height}
+		for i := range a {
+			if math.Abs(a[i]-e[i]) > 1e-6 {
+				t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+				break
+			}
+		}
+	}
+}
+
+func TestGetLetterPositionsInPolygon(t *testing.T) {
+	tests := map[string]struct {
+		polygon [][][]float64
+		label   string
+		lines   []string
+		err     error
+	}{
+		"Square": {
+			squarePolygon,
+			"Lake Windermere North Basin",
+			[]string{"Lake", "Windermere", "North Basin"},
+			nil,
+		},
+		"Holed": {
+			holedPolygon,
+			"Tarn",
+			[]string{"Tarn"},
+			nil,
+		},
+		"Too narrow": {
+			holedPolygon,
+			"Lake Windermere North Basin",
+			nil,
+			ErrTextDontFitPolygon,
+		},
+		"Not a polygon": {
+			[][][]float64{{{0, 0}, {400, 0}}},
+			"Tarn",
+			nil,
+			ErrTextDontFitPolygon,
+		},
+	}
+
+	typeFace := ringTypeFace(t)
+
+	for name, tt := range tests {
+		block, err := GetLetterPositionsInPolygon(tt.label, tt.polygon, typeFace, PolygonOptions{Align: AlignCentre})
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%v: Expected [%v], Got [%v]", name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(tt.lines, block.Lines) {
+			t.Errorf("%v: Expected [%q]\nActual [%q]", name, tt.lines, block.Lines)
+		}
+
+		// every character starts inside the polygon
+		for _, lp := range block.LetterPositions {
+			if !insidePolygon(tt.polygon, lp.X, lp.Y) {
+				t.Errorf("%v: Expected [%+v] to be inside the polygon", name, lp)
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package text
+
+import (
+	"math"
+	"sort"
+)
+
+// Polyline is a line parametrised by the distance along it, the geometry every way of
+// placing a label shares. Distances outside the line are clamped to its ends.
+type Polyline struct {
+	coords   [][]float64
+	lineData MultiLineData
+	// the distance along the line to the start of each segment, plus the total length
+	distances []float64
+}
+
+func NewPolyline(lineCoords [][]float64) *Polyline {
+	lineData := GetLineData(lineCoords)
+	distances := make([]float64, len(lineData)+1)
+
+	for i, ld := range lineData {
+		distances[i+1] = distances[i] + ld.Length
+	}
+
+	return &Polyline{
+		coords:    lineCoords,
+		lineData:  lineData,
+		distances: distances,
+	}
+}
+
+func (p *Polyline) Coords() [][]float64 {
+	return p.coords
+}
+
+func (p *Polyline) LineData() MultiLineData {
+	return p.lineData
+}
+
+func (p *Polyline) Length() float64 {
+	return p.distances[len(p.distances)-1]
+}
+
+// SegmentAt returns the index of the segment containing distance d along the line.
+// At a vertex it is the segment that finishes there.
+func (p *Polyline) SegmentAt(d float64) int {
+	last := len(p.lineData) - 1
+	if last < 0 {
+		return 0
+	}
+
+	i := sort.SearchFloat64s(p.distances, d) - 1
+	if i < 0 {
+		return 0
+	}
+	if i > last {
+		return last
+	}
+
+	return i
+}
+
+// PointAt returns the point d along the line, the origin if the line hasnt got any points.
+func (p *Polyline) PointAt(d float64) (float64, float64) {
+	if len(p.lineData) == 0 {
+		if len(p.coords) == 0 {
+			return 0, 0
+		}
+		return p.coords[0][0], p.coords[0][1]
+	}
+
+	s := p.SegmentAt(d)
+	ld := p.lineData[s]
+
+	t := 0.0
+	if ld.Length > 0 {
+		t = (d - p.distances[s]) / ld.Length
+	}
+	t = math.Max(0, math.Min(1, t))
+
+	return p.coords[s][0] + ld.Pos[0]*t, p.coords[s][1] + ld.Pos[1]*t
+}
+
+// PointOnSegment returns the point along segment s of the line, carrying on in a straight line
+// past the ends of the segment.
+func (p *Polyline) PointOnSegment(s int, along float64) (float64, float64) {
+	x, y := p.coords[s][0], p.coords[s][1]
+	if along == 0 {
+		return x, y
+	}
+
+	dx, dy := p.TangentOnSegment(s)
+
+	return x + dx*along, y + dy*along
+}
+
+// AngleAt returns the angle in degrees of the segment d along the line.
+func (p *Polyline) AngleAt(d float64) float64 {
+	if len(p.lineData) == 0 {
+		return 0
+	}
+
+	return p.lineData[p.SegmentAt(d)].Angle
+}
+
+// TangentAt returns the unit vector in the direction of the line d along it.
+func (p *Polyline) TangentAt(d float64) (float64, float64) {
+	if len(p.lineData) == 0 {
+		return 1, 0
+	}
+
+	return p.TangentOnSegment(p.SegmentAt(d))
+}
+
+// TangentOnSegment returns the unit vector in the direction of segment s of the line.
+func (p *Polyline) TangentOnSegment(s int) (float64, float64) {
+	radians := p.lineData[s].Angle * (math.Pi / 180)
+
+	return math.Cos(radians), math.Sin(radians)
+}
+
+// NormalAt returns the unit vector at right angles to the line d along it, pointing
+// to the left of the direction of travel like offsetLine.
+func (p *Polyline) NormalAt(d float64) (float64, float64) {
+	dx, dy := p.TangentAt(d)
+
+	return dy, -dx
+}
+
+// Project returns the distance along the line to the point on it closest to x, y.
+func (p *Polyline) Project(x, y float64) float64 {
+	var d float64
+	closest := math.Inf(1)
+
+	for s, ld := range p.lineData {
+		t := 0.0
+		if ld.Length > 0 {
+			t = ((x-p.coords[s][0])*ld.Pos[0] + (y-p.coords[s][1])*ld.Pos[1]) / (ld.Length * ld.Length)
+			t = math.Max(0, math.Min(1, t))
+		}
+
+		px := p.coords[s][0] + ld.Pos[0]*t
+		py := p.coords[s][1] + ld.Pos[1]*t
+		if h := math.Hypot(x-px, y-py); h < closest {
+			closest = h
+			d = p.distances[s] + ld.Length*t
+		}
+	}
+
+	return d
+}
+
+// SubPath returns the part of the line between from and to. The points in between
+// are kept as they are, so cutting nothing off gives back the same line.
+func (p *Polyline) SubPath(from, to float64) *Polyline {
+	if len(p.coords) == 0 {
+		return p
+	}
+
+	length := p.Length()
+	coords := p.coords
+
+	// the end first, so the indexes of the start are still good
+	if to < length {
+		if to <= 0 {
+			return NewPolyline(coords[:1])
+		}
+		s := p.SegmentAt(to)
+		x, y := p.PointAt(to)
+		end := make([][]float64, 0, s+2)
+		end = append(end, coords[:s+1]...)
+		coords = append(end, []float64{x, y})
+	}
+
+	if from > 0 {
+		if from >= math.Min(to, length) {
+			return NewPolyline(coords[len(coords)-1:])
+		}
+
+		// the segment the line carries on along after from
+		s := sort.Search(len(p.distances), func(i int) bool { return p.distances[i] > from }) - 1
+		ld := p.lineData[s]
+		t := (from - p.distances[s]) / ld.Length
+		start := []float64{
+			p.coords[s][0] + ld.Pos[0]*t,
+			p.coords[s][1] + ld.Pos[1]*t,
+		}
+		coords = append([][]float64{start}, coords[s+1:]...)
+	}
+
+	return NewPolyline(coords)
+}
+
+// Reverse returns the line running the other way.
+func (p *Polyline) Reverse() *Polyline {
+	reversed := make([][]float64, len(p.coords))
+
+	for i, c := range p.coords {
+		reversed[len(p.coords)-1-i] = c
+	}
+
+	return NewPolyline(reversed)
+}
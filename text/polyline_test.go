@@ -0,0 +1,203 @@
+package text
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+var elbow = [][]float64{{0, 0}, {100, 0}, {100, 50}}
+
+func TestPolylinePointAt(t *testing.T) {
+	tests := map[string]struct {
+		d        float64
+		expected []float64
+		angle    float64
+		normal   []float64
+	}{
+		"Start": {
+			0,
+			[]float64{0, 0},
+			0,
+			[]float64{0, -1},
+		},
+		"Along the first segment": {
+			25,
+			[]float64{25, 0},
+			0,
+			[]float64{0, -1},
+		},
+		"Corner": {
+			100,
+			[]float64{100, 0},
+			0,
+			[]float64{0, -1},
+		},
+		"Along the second segment": {
+			120,
+			[]float64{100, 20},
+			90,
+			[]float64{1, 0},
+		},
+		"Before the start": {
+			-10,
+			[]float64{0, 0},
+			0,
+			[]float64{0, -1},
+		},
+		"Past the end": {
+			200,
+			[]float64{100, 50},
+			90,
+			[]float64{1, 0},
+		},
+	}
+
+	line := NewPolyline(elbow)
+
+	if line.Length() != 150 {
+		t.Errorf("Expected length [150], Got [%v]", line.Length())
+	}
+
+	for name, tt := range tests {
+		x, y := line.PointAt(tt.d)
+		if !closeTo([]float64{x, y}, tt.expected) {
+			t.Errorf("%v: Expected point [%v], Got [%v %v]", name, tt.expected, x, y)
+		}
+
+		if line.AngleAt(tt.d) != tt.angle {
+			t.Errorf("%v: Expected angle [%v], Got [%v]", name, tt.angle, line.AngleAt(tt.d))
+		}
+
+		nx, ny := line.NormalAt(tt.d)
+		if !closeTo([]float64{nx, ny}, tt.normal) {
+			t.Errorf("%v: Expected normal [%v], Got [%v %v]", name, tt.normal, nx, ny)
+		}
+	}
+}
+
+func TestPolylineEmpty(t *testing.T) {
+	line := NewPolyline(nil)
+
+	if line.Length() != 0 {
+		t.Errorf("Expected length [0], Got [%v]", line.Length())
+	}
+
+	x, y := line.PointAt(0)
+	if x != 0 || y != 0 {
+		t.Errorf("Expected point [0 0], Got [%v %v]", x, y)
+	}
+
+	nx, ny := line.NormalAt(0)
+	if nx != 0 || ny != -1 {
+		t.Errorf("Expected normal [0 -1], Got [%v %v]", nx, ny)
+	}
+}
+
+func TestPolylineSubPath(t *testing.T) {
+	tests := map[string]struct {
+		from, to float64
+		expected [][]float64
+	}{
+		"Everything": {
+			0,
+			150,
+			elbow,
+		},
+		"From the middle of a segment": {
+			50,
+			math.Inf(1),
+			[][]float64{{50, 0}, {100, 0}, {100, 50}},
+		},
+		"From the corner": {
+			100,
+			150,
+			[][]float64{{100, 0}, {100, 50}},
+		},
+		"Within one segment": {
+			110,
+			140,
+			[][]float64{{100, 10}, {100, 40}},
+		},
+		"Across the corner": {
+			50,
+			125,
+			[][]float64{{50, 0}, {100, 0}, {100, 25}},
+		},
+		"Past the end": {
+			200,
+			300,
+			[][]float64{{100, 50}},
+		},
+	}
+
+	line := NewPolyline(elbow)
+
+	for name, tt := range tests {
+		actual := line.SubPath(tt.from, tt.to).Coords()
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestPolylineReverse(t *testing.T) {
+	line := NewPolyline(elbow).Reverse()
+
+	expected := [][]float64{{100, 50}, {100, 0}, {0, 0}}
+	if !reflect.DeepEqual(expected, line.Coords()) {
+		t.Errorf("Expected [%+v]\nActual [%+v]", expected, line.Coords())
+	}
+
+	x, y := line.PointAt(25)
+	if !closeTo([]float64{x, y}, []float64{100, 25}) {
+		t.Errorf("Expected [100 25], Got [%v %v]", x, y)
+	}
+
+	if line.AngleAt(100) != 180 {
+		t.Errorf("Expected [180], Got [%v]", line.AngleAt(100))
+	}
+}
+
+func closeTo(a, b []float64) bool {
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-9 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestPolylineProject(t *testing.T) {
+	tests := map[string]struct {
+		point    []float64
+		expected float64
+	}{
+		"Above the first segment": {
+			[]float64{30, -10},
+			30,
+		},
+		"Outside the corner": {
+			[]float64{110, -10},
+			100,
+		},
+		"Beside the second segment": {
+			[]float64{90, 40},
+			140,
+		},
+		"Past the end": {
+			[]float64{100, 80},
+			150,
+		},
+	}
+
+	line := NewPolyline(elbow)
+
+	for name, tt := range tests {
+		if actual := line.Project(tt.point[0], tt.point[1]); math.Abs(actual-tt.expected) > 1e-9 {
+			t.Errorf("%v: Expected [%v], Got [%v]", name, tt.expected, actual)
+		}
+	}
+}
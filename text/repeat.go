@@ -0,0 +1,97 @@
+package text
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+// RepeatOptions controls how often a label is repeated along a line.
+type RepeatOptions struct {
+	// distance along the line between the start of one label and the start of the next
+	Interval float64
+	// the smallest space allowed between the end of one label and the start of the next
+	MinGap float64
+	// distance along the line at which the first label starts
+	StartOffset float64
+}
+
+// TextAlongLineRepeated places the label along the line every repeat.Interval units,
+// returning the glyphs for each label. Positions where the label doesnt fit, or the line
+// is too curvy, are skipped.
+func TextAlongLineRepeated(gc *draw2dimg.GraphicContext, label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions, repeat RepeatOptions) ([][]TextGlyph, error) {
+	labels, err := GetLetterPositionsRepeated(label, lineCoords, tf, opts, repeat)
+	if err != nil {
+		return [][]TextGlyph{}, err
+	}
+
+	textGlyphs := [][]TextGlyph{}
+
+	for _, charpositions := range labels {
+		textGlyphs = append(textGlyphs, toTextGlyphs(charpositions))
+	}
+
+	return textGlyphs, nil
+}
+
+func GetLetterPositionsRepeated(label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions, repeat RepeatOptions) ([][]LetterPosition, error) {
+	lineCoords, opts = prepareLine(lineCoords, opts)
+
+	charMetrics := getCharMetrics(label, tf)
+	width := labelWidth(charMetrics)
+	offset := baselineOffset(tf, opts)
+
+	if opts.Closed {
+		lineCoords = closeRing(lineCoords)
+	}
+
+	length := NewPolyline(offsetLine(lineCoords, offset)).Length()
+	end := length
+
+	// never let the labels overlap
+	step := math.Max(repeat.Interval, width+repeat.MinGap)
+	if step <= 0 {
+		return [][]LetterPosition{}, fmt.Errorf("[%v] the repeat interval must be positive (%v:%v)", label, repeat.Interval, repeat.MinGap)
+	}
+
+	labels := [][]LetterPosition{}
+
+	var lastErr error
+	d := math.Max(0, repeat.StartOffset)
+
+	// on a ring the labels go round once, stopping short of the first one
+	if opts.Closed {
+		d = math.Mod(d, length)
+		end = d + length - repeat.MinGap
+		lineCoords = unrollRing(lineCoords)
+		length = NewPolyline(offsetLine(lineCoords, offset)).Length()
+	}
+
+	for d+width <= end {
+		letterPositions, start, err := placeLabel(label, charMetrics, lineCoords, d, length, width, tf, opts)
+		if err != nil {
+			lastErr = err
+			d += step
+			continue
+		}
+
+		labels = append(labels, letterPositions)
+
+		// the label may have been moved further along to find a straighter stretch of line
+		d = math.Max(d+step, start+width+repeat.MinGap)
+	}
+
+	if len(labels) == 0 {
+		if lastErr != nil {
+			return labels, lastErr
+		}
+
+		fm := fonts.GetFaceMetrics(tf)
+		return labels, fmt.Errorf("[%v] %w [%v:%v] (%v:%v)", label, ErrLettersDontFit, 0, len(charMetrics), fm.Height, tf.Spacing)
+	}
+
+	return labels, nil
+}
@@ -0,0 +1,103 @@
+package text
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+
+	"github.com/rockwell-uk/go-text/fonts"
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestGetLetterPositionsRepeated(t *testing.T) {
+	tests := map[string]struct {
+		points   [][]float64
+		opts     LineOptions
+		repeat   RepeatOptions
+		expected [][]float64
+	}{
+		"Interval": {
+			[][]float64{{0, 0}, {1000, 0}},
+			LineOptions{},
+			RepeatOptions{Interval: 300, StartOffset: 50},
+			[][]float64{{50, 11.33}, {350, 11.33}, {650, 11.33}},
+		},
+		"Min gap": {
+			[][]float64{{0, 0}, {500, 0}},
+			LineOptions{},
+			RepeatOptions{Interval: 50, MinGap: 20},
+			[][]float64{{0, 11.33}, {123.9, 11.33}, {247.8, 11.33}, {371.7, 11.33}},
+		},
+		"Skip corner": {
+			[][]float64{{0, 0}, {400, 0}, {400, 50}, {800, 50}},
+			LineOptions{MaxCharAngleDelta: 45},
+			RepeatOptions{Interval: 200, StartOffset: 150},
+			[][]float64{{150, 11.33}, {500, 61.33}},
+		},
+	}
+
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 34,
+	}
+	face := truetype.NewFace(f, &opts)
+
+	typeFace := fonts.TypeFace{
+		Size: 34,
+		FontData: draw2d.FontData{
+			Name:   "bold",
+			Family: draw2d.FontFamilySans,
+			Style:  draw2d.FontStyleNormal,
+		},
+		Face: face,
+	}
+
+	for name, tt := range tests {
+		labels, err := GetLetterPositionsRepeated("Mellor", tt.points, typeFace, tt.opts, tt.repeat)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		// where the first letter of each label is
+		actual := [][]float64{}
+		for _, l := range labels {
+			actual = append(actual, []float64{
+				math.Round(l[0].X*100) / 100,
+				math.Round(l[0].Y*100) / 100,
+			})
+		}
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestGetLetterPositionsRepeatedTooShort(t *testing.T) {
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 34,
+	}
+	face := truetype.NewFace(f, &opts)
+
+	typeFace := fonts.TypeFace{
+		Size: 34,
+		Face: face,
+	}
+
+	_, err = GetLetterPositionsRepeated("Mellor", [][]float64{{0, 0}, {50, 0}}, typeFace, LineOptions{}, RepeatOptions{Interval: 100})
+	if err == nil {
+		t.Error("Expected an error when the label doesnt fit")
+	}
+}
@@ -0,0 +1,89 @@
+package text
+
+import (
+	"math"
+)
+
+// closeRing makes sure the last point of the ring is the same as the first.
+func closeRing(lineCoords [][]float64) [][]float64 {
+	if len(lineCoords) < 2 {
+		return lineCoords
+	}
+
+	first := lineCoords[0]
+	last := lineCoords[len(lineCoords)-1]
+	if first[0] == last[0] && first[1] == last[1] {
+		return lineCoords
+	}
+
+	ring := make([][]float64, 0, len(lineCoords)+1)
+	ring = append(ring, lineCoords...)
+
+	return append(ring, first)
+}
+
+// unrollRing goes round a closed ring twice, so a label can be placed across the join.
+func unrollRing(ring [][]float64) [][]float64 {
+	if len(ring) < 2 {
+		return ring
+	}
+
+	unrolled := make([][]float64, 0, len(ring)*2-1)
+	unrolled = append(unrolled, ring...)
+
+	return append(unrolled, ring[1:]...)
+}
+
+// centroid returns the average position of the points of a line.
+func centroid(lineCoords [][]float64) (float64, float64) {
+	var x, y float64
+
+	points := lineCoords
+	if len(points) > 1 && points[0][0] == points[len(points)-1][0] && points[0][1] == points[len(points)-1][1] {
+		// dont count the closing point twice
+		points = points[:len(points)-1]
+	}
+
+	for _, p := range points {
+		x += p[0]
+		y += p[1]
+	}
+
+	n := float64(len(points))
+
+	return x / n, y / n
+}
+
+// rayDistance returns the distance along the line to where it is first crossed by
+// a ray from the centroid of the line at angle degrees.
+func rayDistance(lineCoords [][]float64, angle float64) (float64, bool) {
+	if len(lineCoords) < 2 {
+		return 0, false
+	}
+
+	cx, cy := centroid(lineCoords)
+	radians := angle * (math.Pi / 180)
+	rx, ry := math.Cos(radians), math.Sin(radians)
+
+	nearest := math.Inf(1)
+	var distance float64
+	var found bool
+
+	line := NewPolyline(lineCoords)
+	for s, ld := range line.LineData() {
+		// solve centroid + ray*t = start of segment + segment*u
+		denom := rx*ld.Pos[1] - ry*ld.Pos[0]
+		if denom != 0 {
+			qx, qy := lineCoords[s][0]-cx, lineCoords[s][1]-cy
+			t := (qx*ld.Pos[1] - qy*ld.Pos[0]) / denom
+			u := (qx*ry - qy*rx) / denom
+			if t >= 0 && u >= 0 && u <= 1 && t < nearest {
+				nearest = t
+				distance = line.distances[s] + u*ld.Length
+				found = true
+			}
+		}
+	}
+
+	return distance, found
+}
@@ -0,0 +1,254 @@
+package text
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+	textdraw "github.com/rockwell-uk/go-draw/draw"
+
+	"github.com/rockwell-uk/go-text/fonts"
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestRayDistance(t *testing.T) {
+	square := [][]float64{{0, 0}, {100, 0}, {100, 100}, {0, 100}, {0, 0}}
+
+	tests := map[string]struct {
+		angle    float64
+		expected float64
+	}{
+		"Right": {
+			0,
+			150,
+		},
+		"Down": {
+			90,
+			250,
+		},
+		"Up": {
+			-90,
+			50,
+		},
+		"Left": {
+			180,
+			350,
+		},
+	}
+
+	for name, tt := range tests {
+		actual, ok := rayDistance(square, tt.angle)
+		if !ok {
+			t.Fatalf("%v: Expected the ray to cross the ring", name)
+		}
+
+		if math.Abs(tt.expected-actual) > 1e-9 {
+			t.Errorf("%v: Expected [%v], Got [%v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestGetLetterPositionsClosed(t *testing.T) {
+	square := [][]float64{{0, 0}, {100, 0}, {100, 100}, {0, 100}}
+
+	tests := map[string]struct {
+		points [][]float64
+		label  string
+		opts   LineOptions
+		angles []float64
+		err    error
+	}{
+		"Across the join": {
+			square,
+			"Mellor",
+			LineOptions{Closed: true, Anchor: AnchorOffset, Offset: 350},
+			[]float64{-90, -90, 0, 0, 0, 0},
+			nil,
+		},
+		"Offset past the end": {
+			square,
+			"Mellor",
+			LineOptions{Closed: true, Anchor: AnchorOffset, Offset: 750},
+			[]float64{-90, -90, 0, 0, 0, 0},
+			nil,
+		},
+		"Centred on the join": {
+			square,
+			"Mellor",
+			LineOptions{Closed: true, Anchor: AnchorCenterOffset, Offset: 0},
+			[]float64{-90, -90, 0, 0, 0, 0},
+			nil,
+		},
+		"Open": {
+			square,
+			"Mellor",
+			LineOptions{Anchor: AnchorOffset, Offset: 350},
+			nil,
+			ErrLettersDontFit,
+		},
+		"Too long": {
+			square,
+			"Pilsworth Road Pilsworth Road",
+			LineOptions{Closed: true},
+			nil,
+			ErrLettersDontFit,
+		},
+	}
+
+	typeFace := ringTypeFace(t)
+
+	for name, tt := range tests {
+		lps, err := GetLetterPositionsWithOptions(tt.label, tt.points, typeFace, tt.opts)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%v: Expected [%v], Got [%v]", name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		actual := []float64{}
+		for _, lp := range lps {
+			actual = append(actual, math.Round(lp.Angle))
+		}
+
+		if len(actual) != len(tt.angles) {
+			t.Fatalf("%v: Expected [%v], Got [%v]", name, tt.angles, actual)
+		}
+		for i := range actual {
+			if actual[i] != tt.angles[i] {
+				t.Errorf("%v: Expected [%v], Got [%v]", name, tt.angles, actual)
+				break
+			}
+		}
+	}
+}
+
+func TestGetLetterPositionsClosedTruncated(t *testing.T) {
+	square := [][]float64{{0, 0}, {100, 0}, {100, 100}, {0, 100}}
+
+	tests := map[string]struct {
+		opts     LineOptions
+		expected string
+	}{
+		"Centred": {
+			LineOptions{Closed: true, Anchor: AnchorCenter, Truncate: TruncateWord},
+			"Pilsworth Road…",
+		},
+		"Across the join": {
+			LineOptions{Closed: true, Anchor: AnchorOffset, Offset: 350, Truncate: TruncateWord},
+			"Pilsworth Road…",
+		},
+		"By glyph": {
+			LineOptions{Closed: true, Anchor: AnchorEnd, Truncate: TruncateGlyph},
+			"Pilsworth Road Pilswo…",
+		},
+	}
+
+	typeFace := ringTypeFace(t)
+
+	for name, tt := range tests {
+		actual, err := GetLetterPositionsWithOptions("Pilsworth Road Pilsworth Road", square, typeFace, tt.opts)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		// the truncated label goes where it would if it had been given on its own
+		opts := tt.opts
+		opts.Truncate = TruncateNone
+		expected, err := GetLetterPositionsWithOptions(tt.expected, square, typeFace, opts)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%v: Expected [%+v], Got [%+v]", name, expected, actual)
+		}
+	}
+}
+
+func TestGetLetterPositionsAngle(t *testing.T) {
+	circleCoords, err := textdraw.Circle([]float64{0, 0}, 200, 72)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	typeFace := ringTypeFace(t)
+
+	// centred on the top of the circle the label is roughly symmetrical
+	lps, err := GetLetterPositionsWithOptions("MOM", circleCoords, typeFace, LineOptions{Closed: true, Anchor: AnchorAngle, Angle: -90, Smooth: true, KeepUpright: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if math.Abs(lps[1].Angle) > 1 {
+		t.Errorf("Expected the middle letter to be level, Got [%v]", lps[1].Angle)
+	}
+
+	if math.Abs(lps[0].Angle+lps[2].Angle) > 2 {
+		t.Errorf("Expected the outer letters to be symmetrical, Got [%v] [%v]", lps[0].Angle, lps[2].Angle)
+	}
+}
+
+func TestGetLetterPositionsRepeatedClosed(t *testing.T) {
+	square := [][]float64{{0, 0}, {100, 0}, {100, 100}, {0, 100}}
+
+	tests := map[string]struct {
+		repeat   RepeatOptions
+		expected int
+	}{
+		"Interval": {
+			RepeatOptions{Interval: 120},
+			3,
+		},
+		"Touching": {
+			RepeatOptions{Interval: 100},
+			3,
+		},
+		"Gap": {
+			RepeatOptions{Interval: 100, MinGap: 50},
+			2,
+		},
+	}
+
+	typeFace := ringTypeFace(t)
+
+	for name, tt := range tests {
+		labels, err := GetLetterPositionsRepeated("Mellor", square, typeFace, LineOptions{Closed: true}, tt.repeat)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if len(labels) != tt.expected {
+			t.Errorf("%v: Expected [%v] labels, Got [%v]", name, tt.expected, len(labels))
+		}
+	}
+}
+
+func ringTypeFace(t *testing.T) fonts.TypeFace {
+	t.Helper()
+
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 34,
+	}
+
+	return fonts.TypeFace{
+		Size: 34,
+		FontData: draw2d.FontData{
+			Name:   "bold",
+			Family: draw2d.FontFamilySans,
+			Style:  draw2d.FontStyleNormal,
+		},
+		Face: truetype.NewFace(f, &opts),
+	}
+}
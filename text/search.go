@@ -0,0 +1,201 @@
+package text
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/llgcode/draw2d/draw2dimg"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+// SearchOptions controls how positions along a line are tried and scored.
+// Each score is between 0 and 1, the weights default to 1 when they are all 0.
+type SearchOptions struct {
+	// distance along the line between the candidates, defaults to a quarter of the label width
+	Step float64
+	// how straight the line is under the label
+	StraightnessWeight float64
+	// how close the label is to the middle of the line
+	CentreWeight float64
+	// how little the characters turn from one to the next
+	CurvatureWeight float64
+	// how much room there is between the label and the ends of the line
+	FitWeight float64
+}
+
+// Candidate is one position a label can be placed along a line.
+type Candidate struct {
+	LetterPositions []LetterPosition
+	// distance along the line the label starts at, measured along the reversed line when Reversed
+	Start    float64
+	Reversed bool
+	// the weighted score, higher is better, and the scores it is made up from
+	Score        float64
+	Straightness float64
+	Centre       float64
+	Curvature    float64
+	Fit          float64
+}
+
+// TextAlongLineBest places the label at the best scoring position along the line, or the reversed line.
+func TextAlongLineBest(gc *draw2dimg.GraphicContext, label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions, search SearchOptions) ([]TextGlyph, error) {
+	best, err := GetLetterPositionsBest(label, lineCoords, tf, opts, search)
+	if err != nil {
+		return []TextGlyph{}, err
+	}
+
+	return toTextGlyphs(best.LetterPositions), nil
+}
+
+func GetLetterPositionsBest(label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions, search SearchOptions) (Candidate, error) {
+	candidates, err := RankLetterPositions(label, lineCoords, tf, opts, search)
+	if err != nil {
+		return Candidate{}, err
+	}
+
+	return candidates[0], nil
+}
+
+// RankLetterPositions tries the label at positions along the line and the reversed line,
+// returning the ones it fits at, best first. The anchor in opts is ignored and when
+// KeepUpright is set positions where the label would be upside down are left out.
+func RankLetterPositions(label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions, search SearchOptions) ([]Candidate, error) {
+	lineCoords, opts = prepareLine(lineCoords, opts)
+	if opts.Closed {
+		lineCoords = closeRing(lineCoords)
+	}
+
+	charMetrics := getCharMetrics(label, tf)
+	width := labelWidth(charMetrics)
+
+	step := search.Step
+	if step <= 0 {
+		step = math.Max(1, width/4)
+	}
+
+	// every position is tried, so dont search along the line or fall back to shorter labels
+	keepUpright := opts.KeepUpright
+	opts.KeepUpright = false
+	opts.SearchStep = 0
+	opts.Truncate = TruncateNone
+	opts.Anchor = AnchorOffset
+
+	candidates := []Candidate{}
+	var lastErr error
+
+	for _, reversed := range []bool{false, true} {
+		coords := lineCoords
+		if reversed {
+			coords = NewPolyline(lineCoords).Reverse().Coords()
+		}
+
+		// score against the line the label is actually placed along
+		placed := offsetLine(coords, baselineOffset(tf, opts))
+		length := NewPolyline(placed).Length()
+		if opts.Closed {
+			placed = unrollRing(placed)
+		}
+		line := NewPolyline(placed)
+
+		last := length - width
+		if opts.Closed {
+			last = length - step/2
+		}
+
+		starts := candidateStarts(last, step)
+		if !opts.Closed && last > 0 {
+			// make sure the middle is tried
+			starts = append(starts, last/2)
+		}
+
+		for _, start := range starts {
+			opts.Offset = start
+
+			lps, err := GetLetterPositionsWithOptions(label, coords, tf, opts)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			if keepUpright && isUpsideDown(lps, charMetrics) {
+				continue
+			}
+
+			c := Candidate{
+				LetterPositions: lps,
+				Start:           start,
+				Reversed:        reversed,
+			}
+			scoreCandidate(&c, line, length, width, opts.Closed, search)
+			candidates = append(candidates, c)
+		}
+	}
+
+	if len(candidates) == 0 {
+		if lastErr != nil && !errors.Is(lastErr, ErrLettersDontFit) {
+			return candidates, lastErr
+		}
+
+		fm := fonts.GetFaceMetrics(tf)
+		return candidates, fmt.Errorf("[%v] %w [%v:%v] (%v:%v)", label, ErrLettersDontFit, 0, len(charMetrics), fm.Height, tf.Spacing)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	return candidates, nil
+}
+
+// candidateStarts returns every step along the line up to last, and last itself.
+func candidateStarts(last, step float64) []float64 {
+	starts := []float64{}
+	if last < 0 {
+		return starts
+	}
+
+	for d := 0.0; d < last; d += step {
+		starts = append(starts, d)
+	}
+
+	return append(starts, last)
+}
+
+func scoreCandidate(c *Candidate, line *Polyline, length, width float64, closed bool, search SearchOptions) {
+	end := c.Start + width
+
+	// the straight line distance across the label compared to the distance along the line
+	c.Straightness = 1
+	if width > 0 {
+		x0, y0 := line.PointAt(c.Start)
+		x1, y1 := line.PointAt(end)
+		c.Straightness = math.Hypot(x1-x0, y1-y0) / width
+	}
+
+	// rings dont have a middle or ends
+	c.Centre = 1
+	c.Fit = 1
+	if !closed && length > 0 {
+		middle := length / 2
+		c.Centre = 1 - math.Abs(c.Start+width/2-middle)/middle
+
+		if width > 0 {
+			c.Fit = math.Min(1, math.Min(c.Start, length-end)/width)
+		}
+	}
+
+	var turn float64
+	for _, d := range angleDeltas(c.LetterPositions) {
+		turn += math.Abs(d)
+	}
+	c.Curvature = math.Max(0, 1-turn/180)
+
+	sw, cw, kw, fw := search.StraightnessWeight, search.CentreWeight, search.CurvatureWeight, search.FitWeight
+	if sw == 0 && cw == 0 && kw == 0 && fw == 0 {
+		sw, cw, kw, fw = 1, 1, 1, 1
+	}
+
+	c.Score = (c.Straightness*sw + c.Centre*cw + c.Curvature*kw + c.Fit*fw) / (sw + cw + kw + fw)
+}
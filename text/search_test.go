@@ -0,0 +1,90 @@
+package text
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGetLetterPositionsBest(t *testing.T) {
+	tests := map[string]struct {
+		points [][]float64
+		opts   LineOptions
+		search SearchOptions
+		// the range the start has to be in
+		start    []float64
+		reversed bool
+	}{
+		"Centred": {
+			[][]float64{{0, 100}, {400, 100}},
+			LineOptions{},
+			SearchOptions{},
+			[]float64{148.05, 148.05},
+			false,
+		},
+		"Straight stretch": {
+			[][]float64{{0, 100}, {20, 130}, {40, 100}, {60, 130}, {80, 100}, {100, 130}, {120, 100}, {240, 100}},
+			LineOptions{KeepUpright: true},
+			SearchOptions{StraightnessWeight: 1, CurvatureWeight: 1},
+			// the zig zag is 216.33 long
+			[]float64{216.33, 232.43},
+			false,
+		},
+		"Upright": {
+			[][]float64{{400, 100}, {0, 100}},
+			LineOptions{KeepUpright: true},
+			SearchOptions{},
+			[]float64{148.05, 148.05},
+			true,
+		},
+	}
+
+	typeFace := ringTypeFace(t)
+
+	for name, tt := range tests {
+		best, err := GetLetterPositionsBest("Mellor", tt.points, typeFace, tt.opts, tt.search)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if best.Start < tt.start[0]-0.01 || best.Start > tt.start[1]+0.01 {
+			t.Errorf("%v: Expected start [%v], Got [%v]", name, tt.start, best.Start)
+		}
+
+		if best.Reversed != tt.reversed {
+			t.Errorf("%v: Expected reversed [%v], Got [%v]", name, tt.reversed, best.Reversed)
+		}
+
+		for _, lp := range best.LetterPositions {
+			if lp.Angle != 0 {
+				t.Errorf("%v: Expected the label to be level, Got [%+v]", name, best.LetterPositions)
+				break
+			}
+		}
+	}
+}
+
+func TestRankLetterPositions(t *testing.T) {
+	typeFace := ringTypeFace(t)
+
+	candidates, err := RankLetterPositions("Mellor", [][]float64{{0, 100}, {400, 100}}, typeFace, LineOptions{}, SearchOptions{Step: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 0, 50, 100, 150, 200, 250, the end and the middle, both ways
+	if len(candidates) != 16 {
+		t.Errorf("Expected [16] candidates, Got [%v]", len(candidates))
+	}
+
+	for i := 1; i < len(candidates); i++ {
+		if candidates[i].Score > candidates[i-1].Score {
+			t.Errorf("Expected the candidates to be ranked best first")
+			break
+		}
+	}
+
+	_, err = RankLetterPositions("Pilsworth Road", [][]float64{{0, 100}, {100, 100}}, typeFace, LineOptions{}, SearchOptions{})
+	if !errors.Is(err, ErrLettersDontFit) {
+		t.Errorf("Expected [%v], Got [%v]", ErrLettersDontFit, err)
+	}
+}
@@ -0,0 +1,254 @@
+package text
+
+import (
+	"math"
+)
+
+// Simplification is a way of removing unnecessary points from a line.
+type Simplification int
+
+const (
+	SimplifyNone Simplification = iota
+	// remove points closer than the tolerance to the simplified line
+	SimplifyDouglasPeucker
+	// remove points which make triangles smaller than the tolerance with their neighbours
+	SimplifyVisvalingam
+)
+
+// Smoothing is a way of rounding off the corners of a line.
+type Smoothing int
+
+const (
+	SmoothNone Smoothing = iota
+	// cut the corners off, SmoothingPasses times
+	SmoothChaikin
+	// run a curve through the points, adding SmoothingPasses points to each segment
+	SmoothCatmullRom
+)
+
+const (
+	defaultChaikinPasses    = 2
+	defaultCatmullRomPasses = 8
+)
+
+// prepareLine simplifies and smooths the line the label is placed along, returning the
+// options with that turned off so it isnt done again when the label is placed.
+func prepareLine(lineCoords [][]float64, opts LineOptions) ([][]float64, LineOptions) {
+	if opts.Simplify == SimplifyNone && opts.Smoothing == SmoothNone {
+		return lineCoords, opts
+	}
+
+	if opts.Closed {
+		lineCoords = closeRing(lineCoords)
+	}
+
+	switch opts.Simplify {
+	case SimplifyNone:
+	case SimplifyDouglasPeucker:
+		lineCoords = DouglasPeucker(lineCoords, opts.SimplifyTolerance)
+	case SimplifyVisvalingam:
+		lineCoords = Visvalingam(lineCoords, opts.SimplifyTolerance)
+	}
+
+	switch opts.Smoothing {
+	case SmoothNone:
+	case SmoothChaikin:
+		passes := opts.SmoothingPasses
+		if passes <= 0 {
+			passes = defaultChaikinPasses
+		}
+		lineCoords = Chaikin(lineCoords, passes, opts.Closed)
+	case SmoothCatmullRom:
+		passes := opts.SmoothingPasses
+		if passes <= 0 {
+			passes = defaultCatmullRomPasses
+		}
+		lineCoords = CatmullRom(lineCoords, passes, opts.Closed)
+	}
+
+	opts.Simplify = SimplifyNone
+	opts.Smoothing = SmoothNone
+
+	return lineCoords, opts
+}
+
+// DouglasPeucker removes the points of the line that are less than tolerance
+// from the simplified line. The ends are always kept.
+func DouglasPeucker(lineCoords [][]float64, tolerance float64) [][]float64 {
+	if len(lineCoords) < 3 {
+		return lineCoords
+	}
+
+	keep := make([]bool, len(lineCoords))
+	keep[0] = true
+	keep[len(lineCoords)-1] = true
+
+	// work through the stretches of line still to be simplified
+	stack := [][2]int{{0, len(lineCoords) - 1}}
+	for len(stack) > 0 {
+		first, last := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+
+		furthest, index := 0.0, -1
+		for i := first + 1; i < last; i++ {
+			d := distanceToSegment(lineCoords[i], lineCoords[first], lineCoords[last])
+			if d > furthest {
+				furthest, index = d, i
+			}
+		}
+
+		if index != -1 && furthest > tolerance {
+			keep[index] = true
+			stack = append(stack, [2]int{first, index}, [2]int{index, last})
+		}
+	}
+
+	simplified := [][]float64{}
+	for i, c := range lineCoords {
+		if keep[i] {
+			simplified = append(simplified, c)
+		}
+	}
+
+	return simplified
+}
+
+// Visvalingam repeatedly removes the point making the smallest triangle with
+// its neighbours, until every triangle is at least tolerance in area. The ends are always kept.
+func Visvalingam(lineCoords [][]float64, tolerance float64) [][]float64 {
+	simplified := make([][]float64, len(lineCoords))
+	copy(simplified, lineCoords)
+
+	for len(simplified) > 2 {
+		smallest, index := math.Inf(1), -1
+		for i := 1; i < len(simplified)-1; i++ {
+			a := triangleArea(simplified[i-1], simplified[i], simplified[i+1])
+			if a < smallest {
+				smallest, index = a, i
+			}
+		}
+
+		if smallest >= tolerance {
+			break
+		}
+
+		simplified = append(simplified[:index], simplified[index+1:]...)
+	}
+
+	return simplified
+}
+
+// Chaikin cuts the corners off the line, a quarter of the way along each segment,
+// passes times. The ends of an open line are kept where they are.
+func Chaikin(lineCoords [][]float64, passes int, closed bool) [][]float64 {
+	if len(lineCoords) < 3 {
+		return lineCoords
+	}
+
+	smoothed := lineCoords
+	for p := 0; p < passes; p++ {
+		next := [][]float64{}
+		if !closed {
+			next = append(next, smoothed[0])
+		}
+
+		for i := 0; i < len(smoothed)-1; i++ {
+			a, b := smoothed[i], smoothed[i+1]
+			q := []float64{a[0]*0.75 + b[0]*0.25, a[1]*0.75 + b[1]*0.25}
+			r := []float64{a[0]*0.25 + b[0]*0.75, a[1]*0.25 + b[1]*0.75}
+
+			// the open ends arent corners
+			if closed || i > 0 {
+				next = append(next, q)
+			}
+			if closed || i < len(smoothed)-2 {
+				next = append(next, r)
+			}
+		}
+
+		if closed {
+			next = closeRing(next)
+		} else {
+			next = append(next, smoothed[len(smoothed)-1])
+		}
+
+		smoothed = next
+	}
+
+	return smoothed
+}
+
+// CatmullRom runs a Catmull-Rom spline through the points of the line, adding
+// passes points to each segment. The curve goes through every point of the original line.
+func CatmullRom(lineCoords [][]float64, passes int, closed bool) [][]float64 {
+	points := dedupe(lineCoords)
+	if len(points) < 3 {
+		return lineCoords
+	}
+
+	n := len(points)
+	if closed {
+		// dont count the closing point twice
+		n--
+	}
+
+	// the points either side of a segment, mirrored off the ends of an open line
+	at := func(i int) []float64 {
+		switch {
+		case closed:
+			return points[(i%n+n)%n]
+		case i < 0:
+			return []float64{2*points[0][0] - points[1][0], 2*points[0][1] - points[1][1]}
+		case i >= n:
+			return []float64{2*points[n-1][0] - points[n-2][0], 2*points[n-1][1] - points[n-2][1]}
+		}
+		return points[i]
+	}
+
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+
+	smoothed := [][]float64{points[0]}
+	for i := 0; i < segments; i++ {
+		p0, p1, p2, p3 := at(i-1), at(i), at(i+1), at(i+2)
+
+		for s := 1; s <= passes; s++ {
+			t := float64(s) / float64(passes+1)
+			smoothed = append(smoothed, []float64{
+				catmullRomAt(p0[0], p1[0], p2[0], p3[0], t),
+				catmullRomAt(p0[1], p1[1], p2[1], p3[1], t),
+			})
+		}
+		smoothed = append(smoothed, p2)
+	}
+
+	return smoothed
+}
+
+func catmullRomAt(p0, p1, p2, p3, t float64) float64 {
+	return 0.5 * (2*p1 +
+		(p2-p0)*t +
+		(2*p0-5*p1+4*p2-p3)*t*t +
+		(3*p1-p0-3*p2+p3)*t*t*t)
+}
+
+// distanceToSegment returns the shortest distance from p to the segment a, b.
+func distanceToSegment(p, a, b []float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+
+	l := dx*dx + dy*dy
+	if l == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / l
+	t = math.Max(0, math.Min(1, t))
+
+	return math.Hypot(p[0]-(a[0]+dx*t), p[1]-(a[1]+dy*t))
+}
+
+func triangleArea(a, b, c []float64) float64 {
+	return math.Abs((b[0]-a[0])*(c[1]-a[1])-(c[0]-a[0])*(b[1]-a[1])) / 2
+}
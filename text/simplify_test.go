@@ -0,0 +1,154 @@
+package text
+
+import (
+	"reflect"
+	"testing"
+)
+
+// a straight line with a little wobble in it, and a bump in the middle.
+var wobbly = [][]float64{{0, 0}, {10, 1}, {20, -1}, {30, 0}, {40, 20}, {50, 0}, {60, 1}, {70, 0}}
+
+func TestDouglasPeucker(t *testing.T) {
+	tests := map[string]struct {
+		tolerance float64
+		expected  [][]float64
+	}{
+		"Keep the bump": {
+			2,
+			[][]float64{{0, 0}, {30, 0}, {40, 20}, {50, 0}, {70, 0}},
+		},
+		"Flatten everything": {
+			25,
+			[][]float64{{0, 0}, {70, 0}},
+		},
+		"Keep everything": {
+			0,
+			wobbly,
+		},
+	}
+
+	for name, tt := range tests {
+		actual := DouglasPeucker(wobbly, tt.tolerance)
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestVisvalingam(t *testing.T) {
+	tests := map[string]struct {
+		tolerance float64
+		expected  [][]float64
+	}{
+		"Keep the bump": {
+			20,
+			[][]float64{{0, 0}, {30, 0}, {40, 20}, {50, 0}, {70, 0}},
+		},
+		"Flatten everything": {
+			1000,
+			[][]float64{{0, 0}, {70, 0}},
+		},
+		"Keep everything": {
+			0,
+			wobbly,
+		},
+	}
+
+	for name, tt := range tests {
+		actual := Visvalingam(wobbly, tt.tolerance)
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestChaikin(t *testing.T) {
+	tests := map[string]struct {
+		points   [][]float64
+		closed   bool
+		expected [][]float64
+	}{
+		"Open": {
+			[][]float64{{0, 0}, {100, 0}, {100, 100}},
+			false,
+			[][]float64{{0, 0}, {75, 0}, {100, 25}, {100, 100}},
+		},
+		"Closed": {
+			[][]float64{{0, 0}, {100, 0}, {100, 100}, {0, 0}},
+			true,
+			[][]float64{{25, 0}, {75, 0}, {100, 25}, {100, 75}, {75, 75}, {25, 25}, {25, 0}},
+		},
+	}
+
+	for name, tt := range tests {
+		actual := Chaikin(tt.points, 1, tt.closed)
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestCatmullRom(t *testing.T) {
+	tests := map[string]struct {
+		points   [][]float64
+		closed   bool
+		expected [][]float64
+	}{
+		"Straight": {
+			[][]float64{{0, 0}, {10, 0}, {20, 0}},
+			false,
+			[][]float64{{0, 0}, {5, 0}, {10, 0}, {15, 0}, {20, 0}},
+		},
+		"Corner": {
+			[][]float64{{0, 0}, {100, 0}, {100, 100}},
+			false,
+			[][]float64{{0, 0}, {56.25, -6.25}, {100, 0}, {106.25, 43.75}, {100, 100}},
+		},
+		"Closed": {
+			[][]float64{{0, 0}, {100, 0}, {100, 100}, {0, 100}, {0, 0}},
+			true,
+			[][]float64{{0, 0}, {50, -12.5}, {100, 0}, {112.5, 50}, {100, 100}, {50, 112.5}, {0, 100}, {-12.5, 50}, {0, 0}},
+		},
+	}
+
+	for name, tt := range tests {
+		actual := CatmullRom(tt.points, 1, tt.closed)
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestGetLetterPositionsSimplified(t *testing.T) {
+	jagged := [][]float64{}
+	for x := 0.0; x <= 400; x += 4 {
+		y := 100.0
+		if int(x/4)%2 == 1 {
+			y++
+		}
+		jagged = append(jagged, []float64{x, y})
+	}
+
+	typeFace := ringTypeFace(t)
+
+	lps, err := GetLetterPositionsWithOptions("Mellor", jagged, typeFace, LineOptions{Simplify: SimplifyDouglasPeucker, SimplifyTolerance: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, lp := range lps {
+		if lp.Angle != 0 {
+			t.Errorf("Expected the simplified line to be level, Got [%+v]", lps)
+			break
+		}
+	}
+
+	// the line passed in isnt changed
+	if len(jagged) != 101 || jagged[1][1] != 101 {
+		t.Errorf("Expected the line to be left as it was")
+	}
+}
@@ -0,0 +1,190 @@
+package text
+
+import (
+	"math"
+	"strings"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+// SplitPolicy decides whether a label should be split onto more than one line, and how.
+type SplitPolicy struct {
+	// labels with fewer characters than this arent split
+	MinGraphemes int
+	// labels narrower than this arent split, measured with TypeFace so it has to be set
+	MinWidth float64
+	// two word labels arent split when either word has fewer characters than this
+	MinWordGraphemes int
+	// the most lines a label can be split onto, 0 or 1 for two. It needs TypeFace and Aspect,
+	// without them labels are always split in two
+	MaxLines int
+	// the width to height ratio of the split label to aim for when choosing how many
+	// lines to use, measured with TypeFace. 0 always uses two lines
+	Aspect float64
+	// words and phrases that are never split up, matched case sensitively
+	NeverSplit []string
+	// measures the label, without a face labels are split by counting characters
+	TypeFace fonts.TypeFace
+}
+
+// DefaultSplitPolicy returns a policy that splits labels of 12 or more characters in two,
+// unless they are two words and either is shorter than 4 characters. Each call returns a
+// new policy, so changing one doesnt change how ShouldSplit works.
+func DefaultSplitPolicy() SplitPolicy {
+	return SplitPolicy{
+		MinGraphemes:     12,
+		MinWordGraphemes: 4,
+		MaxLines:         2,
+	}
+}
+
+// the space used to hold phrases together while a label is being split.
+const nonBreakingSpace = '\u00a0'
+
+// ShouldSplit reports whether the label is long enough to be split.
+// It can be passed to SplitStringInTwo.
+func (p SplitPolicy) ShouldSplit(s string) bool {
+	s, _ = p.protect(s)
+
+	// dont split short strings
+	if len(graphemes(s)) < p.MinGraphemes {
+		return false
+	}
+
+	if p.MinWidth > 0 && p.TypeFace.Face != nil && measure(s, p.TypeFace) < p.MinWidth {
+		return false
+	}
+
+	// if there arent any spaces dont split
+	if !strings.Contains(s, " ") {
+		return false
+	}
+
+	// if the first or second of two words is short dont split
+	words := strings.Split(s, " ")
+	if len(words) == 2 {
+		for _, w := range words {
+			if len(graphemes(w)) < p.MinWordGraphemes {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Split splits the label onto lines according to the policy. With a TypeFace the lines are
+// balanced by their measured widths, otherwise it is split in two like SplitStringInTwo.
+func (p SplitPolicy) Split(s string) []string {
+	if !p.ShouldSplit(s) {
+		return []string{s}
+	}
+
+	protected, added := p.protect(s)
+
+	var lines []string
+	if p.TypeFace.Face == nil {
+		lines = SplitStringInTwo(protected, func(string) bool { return true })
+	} else {
+		lines = p.wrap(protected)
+	}
+
+	return unprotect(lines, added)
+}
+
+// wrap the label onto the number of lines that gets it closest to the aspect ratio.
+func (p SplitPolicy) wrap(s string) []string {
+	maxLines := p.MaxLines
+	if maxLines < 2 {
+		maxLines = 2
+	}
+
+	best := Wrap(s, p.TypeFace, WrapOptions{Lines: 2})
+	if p.Aspect <= 0 {
+		return best
+	}
+
+	height := fonts.GetFaceMetrics(p.TypeFace).Height
+	bestDiff := math.Inf(1)
+
+	for n := 2; n <= maxLines; n++ {
+		lines := Wrap(s, p.TypeFace, WrapOptions{Lines: n})
+		if len(lines) < n {
+			// run out of words
+			break
+		}
+
+		var width float64
+		for _, l := range lines {
+			width = math.Max(width, measure(l, p.TypeFace))
+		}
+
+		diff := math.Abs(width/(height*float64(n)) - p.Aspect)
+		if diff < bestDiff {
+			best, bestDiff = lines, diff
+		}
+	}
+
+	return best
+}
+
+// protect joins the words of the phrases that shouldnt be split with non breaking spaces,
+// and reports which of the non breaking spaces in the result it added.
+func (p SplitPolicy) protect(s string) (string, []bool) {
+	joined := map[int]bool{}
+	for _, phrase := range p.NeverSplit {
+		if phrase == "" {
+			continue
+		}
+		for i := strings.Index(s, phrase); i >= 0; {
+			for j, r := range phrase {
+				if r == ' ' {
+					joined[i+j] = true
+				}
+			}
+			next := strings.Index(s[i+len(phrase):], phrase)
+			if next < 0 {
+				break
+			}
+			i += len(phrase) + next
+		}
+	}
+
+	var b strings.Builder
+	var added []bool
+	for i, r := range s {
+		switch {
+		case joined[i]:
+			b.WriteRune(nonBreakingSpace)
+			added = append(added, true)
+		case r == nonBreakingSpace:
+			b.WriteRune(r)
+			added = append(added, false)
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String(), added
+}
+
+// unprotect turns the non breaking spaces protect added back into spaces, leaving the
+// ones that were in the label. The lines are the protected label split at its spaces.
+func unprotect(lines []string, added []bool) []string {
+	n := 0
+	for i, l := range lines {
+		var b strings.Builder
+		for _, r := range l {
+			if r == nonBreakingSpace {
+				if added[n] {
+					r = ' '
+				}
+				n++
+			}
+			b.WriteRune(r)
+		}
+		lines[i] = b.String()
+	}
+
+	return lines
+}
@@ -0,0 +1,124 @@
+package text
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitPolicyShouldSplit(t *testing.T) {
+	tests := map[string]struct {
+		policy   SplitPolicy
+		label    string
+		expected bool
+	}{
+		"Default short": {
+			DefaultSplitPolicy(),
+			"High Street",
+			false,
+		},
+		"Default no spaces": {
+			DefaultSplitPolicy(),
+			"Wolverhampton",
+			false,
+		},
+		"Default short word": {
+			DefaultSplitPolicy(),
+			"Wolverhampton Rd",
+			false,
+		},
+		"Default": {
+			DefaultSplitPolicy(),
+			"Pilsworth Road",
+			true,
+		},
+		"Default accents": {
+			DefaultSplitPolicy(),
+			"Café de Flore",
+			true,
+		},
+		"Shorter": {
+			SplitPolicy{MinGraphemes: 6, MinWordGraphemes: 4},
+			"Bury Road",
+			true,
+		},
+		"Never split": {
+			SplitPolicy{MinGraphemes: 12, NeverSplit: []string{"Ashton under Lyne"}},
+			"Ashton under Lyne",
+			false,
+		},
+	}
+
+	for name, tt := range tests {
+		actual := tt.policy.ShouldSplit(tt.label)
+
+		if actual != tt.expected {
+			t.Errorf("%v: Expected [%v], Got [%v]", name, tt.expected, actual)
+		}
+	}
+
+	// changing a default policy doesnt change the default
+	policy := DefaultSplitPolicy()
+	policy.MinGraphemes = 6
+	if !policy.ShouldSplit("Bury Road") || ShouldSplit("Bury Road") {
+		t.Errorf("Expected only the changed policy to split [Bury Road]")
+	}
+}
+
+func TestSplitPolicySplit(t *testing.T) {
+	typeFace := ringTypeFace(t)
+
+	tests := map[string]struct {
+		policy   SplitPolicy
+		label    string
+		expected []string
+	}{
+		"Default": {
+			DefaultSplitPolicy(),
+			"Pilsworth Road",
+			[]string{"Pilsworth", "Road"},
+		},
+		"Never split": {
+			SplitPolicy{MinGraphemes: 12, NeverSplit: []string{"Ashton under Lyne"}},
+			"Ashton under Lyne Road",
+			[]string{"Ashton under Lyne", "Road"},
+		},
+		"Non breaking spaces": {
+			DefaultSplitPolicy(),
+			"Stoke\u00a0on\u00a0Trent Road",
+			[]string{"Stoke\u00a0on\u00a0Trent", "Road"},
+		},
+		"Never split and non breaking spaces": {
+			SplitPolicy{MinGraphemes: 12, NeverSplit: []string{"Ashton under Lyne"}},
+			"Ashton under Lyne Bus\u00a0Station",
+			[]string{"Ashton under Lyne", "Bus\u00a0Station"},
+		},
+		"Measured": {
+			SplitPolicy{MinGraphemes: 12, TypeFace: typeFace},
+			"The Royal Exchange Theatre",
+			[]string{"The Royal", "Exchange Theatre"},
+		},
+		"Narrow": {
+			SplitPolicy{MinWidth: 600, TypeFace: typeFace},
+			"The Royal Exchange Theatre",
+			[]string{"The Royal Exchange Theatre"},
+		},
+		"Square": {
+			SplitPolicy{MinGraphemes: 12, MaxLines: 4, Aspect: 1, TypeFace: typeFace},
+			"Newcastle upon Tyne and Wear",
+			[]string{"Newcastle", "upon", "Tyne and", "Wear"},
+		},
+		"Wide": {
+			SplitPolicy{MinGraphemes: 12, MaxLines: 4, Aspect: 3, TypeFace: typeFace},
+			"Newcastle upon Tyne and Wear",
+			[]string{"Newcastle upon", "Tyne and Wear"},
+		},
+	}
+
+	for name, tt := range tests {
+		actual := tt.policy.Split(tt.label)
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%q], Got [%q]", name, tt.expected, actual)
+		}
+	}
+}
@@ -1,7 +1,10 @@
 package text
 
 import (
+	"math"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/rockwell-uk/go-text/fonts"
 )
@@ -18,27 +21,31 @@ func SplitStringInTwo(s string, split func(string) bool) []string {
 		return strings.Split(s, " ")
 	}
 
-	l := len(s)
+	l := utf8.RuneCountInString(s)
 
-	// figure out positions of spaces
+	// figure out positions of spaces, both in runes and in bytes
 	sp := []int{}
-	for i := 0; i < l; i++ {
-		if string(s[i]) == " " {
-			sp = append(sp, i)
+	bp := []int{}
+	n = 0
+	for i, r := range s {
+		if r == ' ' {
+			sp = append(sp, n)
+			bp = append(bp, i)
 		}
+		n++
 	}
 
 	// decide which line each word belongs on
 	dist := l / 2
 	pos := 0
-	for _, o := range sp {
+	for j, o := range sp {
 		d := dist - o
 		if d < 0 {
 			d = -d
 		}
 		if d < dist {
 			dist = d
-			pos = o
+			pos = bp[j]
 		}
 	}
 
@@ -48,6 +55,43 @@ func SplitStringInTwo(s string, split func(string) bool) []string {
 	}
 }
 
+// SplitStringInTwoMeasured splits s on the space that best balances the
+// measured widths of the two lines, taking kerning and spacing into account.
+func SplitStringInTwoMeasured(s string, tf fonts.TypeFace, split func(string) bool) []string {
+	if !split(s) {
+		return []string{s}
+	}
+
+	best := -1
+	bestDiff := math.Inf(1)
+	for i, r := range s {
+		if r != ' ' {
+			continue
+		}
+
+		diff := math.Abs(measure(s[:i], tf) - measure(s[i+1:], tf))
+		if diff < bestDiff {
+			bestDiff = diff
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return []string{s}
+	}
+
+	return []string{
+		s[0:best],
+		s[best+1:],
+	}
+}
+
+// measure the width a string takes up when laid out with tf, the same width it takes up
+// when it is placed along a line.
+func measure(s string, tf fonts.TypeFace) float64 {
+	return labelWidth(getCharMetrics(s, tf))
+}
+
 func getFullWidth(gm fonts.GlyphMetrics) float64 {
 	return gm.Advance
 }
@@ -56,31 +100,31 @@ func getFullHeight(gm fonts.GlyphMetrics) float64 {
 	return gm.Ascent
 }
 
+// ShouldSplit reports whether the label should be split according to DefaultSplitPolicy.
 func ShouldSplit(s string) bool {
-	// dont split short strings
-	if len(s) < 12 {
-		return false
-	}
-
-	n := strings.Count(s, " ")
-
-	// if there arent any spaces dont split
-	if n == 0 {
-		return false
-	}
+	return DefaultSplitPolicy().ShouldSplit(s)
+}
 
-	words := strings.Split(s, " ")
+// combining marks (accents, variation selectors, joiners) are drawn over or
+// joined to the preceding character rather than occupying a position of their own.
+func isCombining(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me) || r == '\u200d' || unicode.Is(unicode.Variation_Selector, r)
+}
 
-	// if there are 2 words
-	// if the first or secord word is short dont split
-	if len(words) == 2 {
-		if len(words[0]) < 4 {
-			return false
-		}
-		if len(words[1]) < 4 {
-			return false
+// graphemes splits s into the characters a reader would see, keeping
+// combining marks and joined sequences with the character they belong to.
+func graphemes(s string) []string {
+	var g []string
+	var joining bool
+
+	for i, r := range s {
+		if len(g) > 0 && (joining || isCombining(r)) {
+			g[len(g)-1] += string(r)
+		} else {
+			g = append(g, s[i:i+utf8.RuneLen(r)])
 		}
+		joining = r == '\u200d'
 	}
 
-	return true
+	return g
 }
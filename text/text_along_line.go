@@ -1,8 +1,10 @@
 package text
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"unicode/utf8"
 
 	"github.com/llgcode/draw2d/draw2dimg"
 
@@ -15,115 +17,323 @@ type TextGlyph struct {
 	Rotation float64
 }
 
+// ErrLettersDontFit is returned when the label is too long for the line it is being placed along.
+var ErrLettersDontFit = errors.New("the letters dont fit on the line")
+
 func TextAlongLine(gc *draw2dimg.GraphicContext, label string, lineCoords [][]float64, tf fonts.TypeFace) ([]TextGlyph, error) {
-	charpositions, err := GetLetterPositions(label, lineCoords, tf)
+	return TextAlongLineWithOptions(gc, label, lineCoords, tf, LineOptions{})
+}
+
+func TextAlongLineWithOptions(gc *draw2dimg.GraphicContext, label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions) ([]TextGlyph, error) {
+	charpositions, err := GetLetterPositionsWithOptions(label, lineCoords, tf, opts)
 	if err != nil {
 		return []TextGlyph{}, err
 	}
 
+	return toTextGlyphs(charpositions), nil
+}
+
+func toTextGlyphs(charpositions []LetterPosition) []TextGlyph {
 	textGlyphs := []TextGlyph{}
 
-	for i, c := range label {
-		x := charpositions[i].X
-		y := charpositions[i].Y
-		rotation := charpositions[i].Angle
+	for _, cp := range charpositions {
+		c, _ := utf8.DecodeRuneInString(cp.Char)
 		pos := []float64{
-			x,
-			y,
+			cp.X,
+			cp.Y,
 		}
 
-		textGlyphs = append(textGlyphs, TextGlyph{Char: c, Pos: pos, Rotation: rotation})
+		textGlyphs = append(textGlyphs, TextGlyph{Char: c, Pos: pos, Rotation: cp.Angle})
 	}
 
-	return textGlyphs, nil
+	return textGlyphs
 }
 
 func GetLetterPositions(label string, lineCoords [][]float64, tf fonts.TypeFace) ([]LetterPosition, error) {
+	return GetLetterPositionsWithOptions(label, lineCoords, tf, LineOptions{})
+}
+
+func GetLetterPositionsWithOptions(label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions) ([]LetterPosition, error) {
+	letterPositions, err := placeAlongLine(label, lineCoords, tf, opts)
+	if opts.Truncate != TruncateNone && errors.Is(err, ErrLettersDontFit) {
+		// start again from the line as it was given, not as it was prepared or unrolled
+		return truncateAlongLine(label, lineCoords, tf, opts, letterPositions, err)
+	}
+
+	return letterPositions, err
+}
+
+// placeAlongLine places the whole label along the line, going round it when it is a closed ring.
+func placeAlongLine(label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions) ([]LetterPosition, error) {
+	lineCoords, opts = prepareLine(lineCoords, opts)
+
 	charMetrics := getCharMetrics(label, tf)
+	width := labelWidth(charMetrics)
+	offset := baselineOffset(tf, opts)
+
+	if opts.Closed {
+		lineCoords = closeRing(lineCoords)
+	}
+
+	line := offsetLine(lineCoords, offset)
+	length := NewPolyline(line).Length()
+
+	// work out how far along the line the label starts
+	start := anchorDistance(opts, line, length, width)
+
+	if opts.Closed {
+		if width > length {
+			fm := fonts.GetFaceMetrics(tf)
+			return []LetterPosition{},
+				fmt.Errorf("[%v] %w [%v:%v] (%v:%v)", label, ErrLettersDontFit, 0, len(charMetrics), fm.Height, tf.Spacing)
+		}
+
+		// go round the ring twice so the label can run across the join
+		start = math.Mod(start, length)
+		if start < 0 {
+			start += length
+		}
+		lineCoords = unrollRing(lineCoords)
+		length = NewPolyline(offsetLine(lineCoords, offset)).Length()
+	}
+
+	letterPositions, _, err := placeLabel(label, charMetrics, lineCoords, start, length, width, tf, opts)
+
+	return letterPositions, err
+}
 
-	lineData := GetLineData(lineCoords)
-	letterPositions := calculateLetterPositions(label, charMetrics, lineData, lineCoords, tf)
+// placeLabel places the label start units along the line, returning where it was actually placed.
+func placeLabel(label string, charMetrics []CharMetric, lineCoords [][]float64, start, length, width float64, tf fonts.TypeFace, opts LineOptions) ([]LetterPosition, float64, error) {
+	for {
+		letterPositions, err := placeUpright(label, charMetrics, lineCoords, start, length, width, tf, opts)
+		if err == nil {
+			err = checkCurvature(label, letterPositions, opts)
+		}
+
+		// if the line is too curvy here, try further along
+		if !errors.Is(err, ErrTooCurvy) || opts.SearchStep <= 0 {
+			return letterPositions, start, err
+		}
+
+		start += opts.SearchStep
+		if start+width > length {
+			return letterPositions, start, err
+		}
+	}
+}
+
+// placeUpright places the label along the line, offset to the side if required, running
+// it the other way along the line when KeepUpright is set and it would be upside down.
+// start and length are measured along the offset line.
+func placeUpright(label string, charMetrics []CharMetric, lineCoords [][]float64, start, length, width float64, tf fonts.TypeFace, opts LineOptions) ([]LetterPosition, error) {
+	offset := baselineOffset(tf, opts)
+
+	letterPositions, err := placeLetters(label, charMetrics, offsetLine(lineCoords, offset), start, tf, opts)
+	if !opts.KeepUpright || !isUpsideDown(letterPositions, charMetrics) {
+		return letterPositions, err
+	}
+
+	// run the label along the line the other way, covering the same stretch of line
+	// the other way the offset line is on the other side, so scale to its length
+	reversedLine := offsetLine(NewPolyline(lineCoords).Reverse().Coords(), offset)
+	reversedStart := length - start - width
+	if offset != 0 && length > 0 {
+		reversedStart *= NewPolyline(reversedLine).Length() / length
+	}
+
+	reversed, rerr := placeLetters(label, charMetrics, reversedLine, reversedStart, tf, opts)
+	if rerr != nil && err == nil {
+		return letterPositions, nil
+	}
+
+	return reversed, rerr
+}
+
+func placeLetters(label string, charMetrics []CharMetric, lineCoords [][]float64, start float64, tf fonts.TypeFace, opts LineOptions) ([]LetterPosition, error) {
+	labelLength := len(charMetrics)
+
+	if start < 0 {
+		fm := fonts.GetFaceMetrics(tf)
+		return []LetterPosition{},
+			fmt.Errorf("[%v] %w at %v [%v:%v] (%v:%v)", label, ErrLettersDontFit, opts.Anchor, 0, labelLength, fm.Height, tf.Spacing)
+	}
+
+	var letterPositions []LetterPosition
+	if opts.Smooth {
+		letterPositions = calculateSmoothLetterPositions(charMetrics, lineCoords, start, tf)
+	} else {
+		line := NewPolyline(lineCoords).SubPath(start, math.Inf(1))
+		letterPositions = calculateLetterPositions(charMetrics, line, tf)
+	}
 
 	numPositions := len(letterPositions)
-	labelLength := len(label)
 
 	if numPositions < labelLength {
 		fm := fonts.GetFaceMetrics(tf)
 		return letterPositions,
-			fmt.Errorf("[%v] the letters dont fit on the line [%v:%v] (%v:%v)", label, numPositions, labelLength, fm.Height, tf.Spacing)
+			fmt.Errorf("[%v] %w [%v:%v] (%v:%v)", label, ErrLettersDontFit, numPositions, labelLength, fm.Height, tf.Spacing)
 	}
 
 	return letterPositions, nil
 }
 
-func calculateLetterPositions(label string, charMetrics []CharMetric, lineData []LineData, lineCoords [][]float64, tf fonts.TypeFace) []LetterPosition {
+// a label is upside down when most of it (by width) is drawn at an angle beyond +/-90 degrees.
+func isUpsideDown(letterPositions []LetterPosition, charMetrics []CharMetric) bool {
+	var inverted, total float64
+
+	for i, lp := range letterPositions {
+		w := charMetrics[i].Width
+		if math.Abs(lp.Angle) > 90 {
+			inverted += w
+		}
+		total += w
+	}
+
+	return inverted > total/2
+}
+
+// the distance along the line at which the first character should be placed
+// a negative distance means the label cannot be placed at the anchor.
+func anchorDistance(opts LineOptions, lineCoords [][]float64, lineLength, labelWidth float64) float64 {
+	switch opts.Anchor {
+	case AnchorStart:
+		return 0
+	case AnchorCenter:
+		return (lineLength - labelWidth) / 2
+	case AnchorEnd:
+		return lineLength - labelWidth
+	case AnchorOffset:
+		if opts.Offset > lineLength && !opts.Closed {
+			return -1
+		}
+		return opts.Offset
+	case AnchorCenterOffset:
+		return centredStart(opts, opts.Offset, lineLength, labelWidth)
+	case AnchorAngle:
+		d, ok := rayDistance(lineCoords, opts.Angle)
+		if !ok {
+			return -1
+		}
+		return centredStart(opts, d, lineLength, labelWidth)
+	}
+
+	return 0
+}
+
+// where the label has to start to be centred on d, on a ring it can wrap round past the start.
+func centredStart(opts LineOptions, d, lineLength, labelWidth float64) float64 {
+	start := d - labelWidth/2
+	if opts.Closed && start < 0 {
+		start += lineLength
+	}
+
+	return start
+}
+
+// labelWidth is the width of the label from the start of its first character to the end of
+// its last, including kerning and the spacing between characters.
+func labelWidth(charMetrics []CharMetric) float64 {
+	var w float64
+
+	for _, cm := range charMetrics {
+		w += cm.Width
+	}
+
+	return w
+}
+
+// calculateLetterPositions places the characters one after the other along each segment
+// of the line, at the angle of the segment. A character goes on the next segment when no
+// more than half of it fits on this one, carrying on from where the last one finished.
+func calculateLetterPositions(charMetrics []CharMetric, line *Polyline, tf fonts.TypeFace) []LetterPosition {
 	var letterPositions []LetterPosition
-	var charIndex int         // index of the current character
-	var charsOnSegment int    // number of characters on the current segment
-	var charMetric CharMetric // character metrics
-	var remainder float64     // what is left of the current segment
-	var nudge float64         // how far do we need to nudge the first char on the next segment
-	var positionX, positionY float64
-	var offsetX, offsetY float64
+	var along float64 // how far along the segment the next character starts, before it when carrying on
 
 	fm := fonts.GetFaceMetrics(tf)
 
-	charIndex = 0
-	nudge = 0
+	charIndex := 0
+	for s, segment := range line.LineData() {
+		x, y := line.PointOnSegment(s, along)
+		dx, dy := line.TangentOnSegment(s)
+		remainder := segment.Length - along
+
+		charsOnSegment := 0
+		for ; charIndex < len(charMetrics); charIndex++ {
+			charMetric := charMetrics[charIndex]
+			if remainder <= charMetric.Width/2 {
+				break
+			}
 
-	for s, line := range lineData {
-		// reset segment count
-		charsOnSegment = 0
+			// offset required for each character to be centered on the line
+			letterPositions = append(letterPositions, LetterPosition{
+				Char:  charMetric.Char,
+				X:     x - dy*fm.Height/3,
+				Y:     y + dx*fm.Height/3,
+				Angle: segment.Angle,
+			})
 
-		// starting point of the current line
-		lineCoord := lineCoords[s]
+			// move along the line
+			x += dx * charMetric.Width
+			y += dy * charMetric.Width
+			remainder -= charMetric.Width
+			charsOnSegment++
+		}
 
-		// offset required for each character to be centered on the line
-		offsetX = math.Sin(line.Angle*(math.Pi/180)) * fm.Height / 3
-		offsetY = math.Cos(line.Angle*(math.Pi/180)) * fm.Height / 3
+		// the next segment starts afresh if nothing fitted on this one
+		along = 0
+		if charsOnSegment > 0 {
+			along = -remainder
+		}
+	}
+
+	return letterPositions
+}
+
+// calculateSmoothLetterPositions places each character by the centre of its advance, at its
+// distance along the line, rotated to the tangent of the line there. The tangent is interpolated
+// across the width of the character, so the rotation changes gradually round bends rather
+// than snapping between segments.
+func calculateSmoothLetterPositions(charMetrics []CharMetric, lineCoords [][]float64, start float64, tf fonts.TypeFace) []LetterPosition {
+	var letterPositions []LetterPosition
 
-		remainder = line.Length
+	fm := fonts.GetFaceMetrics(tf)
+	line := NewPolyline(lineCoords)
+	if len(line.LineData()) == 0 {
+		return letterPositions
+	}
 
-		for i := charIndex; i < len(label); i++ {
-			charMetric = charMetrics[charIndex]
-			charWidth := charMetric.Width
+	length := line.Length()
 
-			// calculate the x and y increase depending on the angle of the line
-			xIncrease := math.Cos(line.Angle*(math.Pi/180)) * charWidth
-			yIncrease := math.Sin(line.Angle*(math.Pi/180)) * charWidth
+	d := start
+	for _, charMetric := range charMetrics {
+		half := charMetric.Metrics.Advance / 2
+		centre := d + half
 
-			// if this is the first loop in the current segment we need a starting point
-			if charsOnSegment == 0 {
-				positionX = lineCoord[0]
-				positionY = lineCoord[1]
-				if nudge != 0 {
-					remainder -= nudge
-					positionX += math.Cos(line.Angle*(math.Pi/180)) * nudge
-					positionY += math.Sin(line.Angle*(math.Pi/180)) * nudge
-					nudge = 0
-				}
-			}
+		// the centre of the character has to be on the line
+		if centre > length {
+			break
+		}
 
-			if remainder > charWidth/2 {
-				letterPositions = append(letterPositions, LetterPosition{
-					Char:  charMetric.Char,
-					X:     positionX - offsetX,
-					Y:     positionY + offsetY,
-					Angle: line.Angle,
-				})
-
-				// move along the line
-				positionX += xIncrease
-				positionY += yIncrease
-
-				// increase counts
-				remainder -= charWidth
-				charsOnSegment++
-				charIndex++
-			} else if charsOnSegment > 0 {
-				nudge = -remainder
-			}
+		// direction of the line across the width of the character
+		x0, y0 := line.PointAt(centre - half)
+		x1, y1 := line.PointAt(centre + half)
+		angle := math.Atan2(y1-y0, x1-x0)
+		if half == 0 {
+			angle = line.AngleAt(centre) * (math.Pi / 180)
 		}
+
+		cx, cy := line.PointAt(centre)
+
+		// step back from the centre to where the character starts, then
+		// offset it so the character is centred on the line
+		letterPositions = append(letterPositions, LetterPosition{
+			Char:  charMetric.Char,
+			X:     cx - math.Cos(angle)*half - math.Sin(angle)*fm.Height/3,
+			Y:     cy - math.Sin(angle)*half + math.Cos(angle)*fm.Height/3,
+			Angle: angle / (math.Pi / 180),
+		})
+
+		d += charMetric.Width
 	}
 
 	return letterPositions
@@ -132,11 +342,31 @@ func calculateLetterPositions(label string, charMetrics []CharMetric, lineData [
 func getCharMetrics(label string, tf fonts.TypeFace) []CharMetric {
 	charMetrics := []CharMetric{}
 
-	for i, r := range label {
+	runes := []rune(label)
+
+	// the spacing goes between characters, so none after the last one
+	last := len(runes) - 1
+	for last >= 0 && isCombining(runes[last]) {
+		last--
+	}
+
+	for i, r := range runes {
+		width := fonts.GetGlyphWidth(tf, r)
+
+		// combining marks belong to the preceding character so they dont get any extra spacing
+		if !isCombining(r) && i < last {
+			width += tf.Spacing
+		}
+
+		// pull the next character in (or push it away) according to the font's kerning
+		if i+1 < len(runes) {
+			width += fonts.GetKerning(tf, r, runes[i+1])
+		}
+
 		charMetrics = append(charMetrics, CharMetric{
-			Char:    string(label[i]),
+			Char:    string(r),
 			Metrics: fonts.GetGlyphMetrics(tf, r),
-			Width:   fonts.GetGlyphWidth(tf, r) + tf.Spacing,
+			Width:   width,
 		})
 	}
 
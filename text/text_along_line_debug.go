@@ -3,6 +3,7 @@ package text
 import (
 	"image/color"
 	"math"
+	"unicode/utf8"
 
 	"github.com/llgcode/draw2d/draw2dimg"
 	"github.com/rockwell-uk/go-draw/draw"
@@ -25,8 +26,9 @@ func DrawGlyphOutlines(gc *draw2dimg.GraphicContext, label string, lineCoords []
 		return x, y
 	}
 
-	if len(letterpositions) >= len(label) {
-		for i, r := range label {
+	if len(letterpositions) >= utf8.RuneCountInString(label) {
+		i := 0
+		for _, r := range label {
 			x := letterpositions[i].X
 			y := letterpositions[i].Y
 
@@ -55,6 +57,8 @@ func DrawGlyphOutlines(gc *draw2dimg.GraphicContext, label string, lineCoords []
 			if err != nil {
 				return err
 			}
+
+			i++
 		}
 	}
 
@@ -1,10 +1,12 @@
 package text
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
+	"math"
 	"os"
 	"path"
 	"reflect"
@@ -47,6 +49,13 @@ func TestGetCharMetrics(t *testing.T) {
 			float64(0),
 			[]float64{32.09, 18.91, 9.45, 9.45, 20.77, 13.23, 9.45, 22.67, 13.23, 13.23, 18.91, 18.91, 13.23},
 		},
+		"Straße": {
+			"MULTILINESTRING((384342 409455.9999997657,384476.99999999994 409567.9999997657,384504.99999999994 409597.9999997654,384563.00000000006 409669.9999997661))",
+			"Straße",
+			float64(34),
+			float64(0),
+			[]float64{22.67, 13.23, 13.23, 18.91, 22.67, 18.91},
+		},
 	}
 
 	for name, tt := range tests {
@@ -205,6 +214,51 @@ func TestGetLetterPositions(t *testing.T) {
 				{Char: "d", X: 391050.0298209829, Y: 411624.1431488674, Angle: 49.49715161429619},
 			},
 		},
+		"Улица Ленина": {
+			[][]float64{{384342, 409455.9999997657}, {384476.99999999994, 409567.9999997657}, {384504.99999999994, 409597.9999997654}, {384563.00000000006, 409669.9999997661}},
+			"Улица Ленина",
+			float64(34),
+			float64(0),
+			float64(1),
+			[]LetterPosition{
+				{Char: "У", X: 384334.76365949906, Y: 409464.72237447667, Angle: 39.68010608220529},
+				{Char: "л", X: 384353.65786236565, Y: 409480.397565003, Angle: 39.68010608220529},
+				{Char: "и", X: 384369.3658330584, Y: 409493.4293629111, Angle: 39.68010608220529},
+				{Char: "ц", X: 384385.3508674184, Y: 409506.69102104683, Angle: 39.68010608220529},
+				{Char: "а", X: 384401.70532000146, Y: 409520.25915948616, Angle: 39.68010608220529},
+				{Char: " ", X: 384416.25885874714, Y: 409532.33320644556, Angle: 39.68010608220529},
+				{Char: "Л", X: 384423.5317800135, Y: 409538.3670374221, Angle: 39.68010608220529},
+				{Char: "е", X: 384442.4259828801, Y: 409554.04222794843, Angle: 39.68010608220529},
+				{Char: "н", X: 384456.9795216258, Y: 409566.11627490784, Angle: 39.68010608220529},
+				{Char: "и", X: 384471.5525053629, Y: 409578.7734382254, Angle: 46.97493401060472},
+				{Char: "н", X: 384485.72425542295, Y: 409593.9574561467, Angle: 46.97493401060472},
+				{Char: "а", X: 384499.09903684637, Y: 409608.74066285137, Angle: 51.14662565986203},
+			},
+		},
+		"Rue de l'Église": {
+			[][]float64{{390902, 411492.9999997673}, {390951.00000000006, 411523.99999976787}, {391010, 411571.999999767}, {391052, 411608.9999997665}, {391092.99999999994, 411656.99999976583}},
+			"Rue de l'Église",
+			float64(34),
+			float64(0),
+			float64(1),
+			[]LetterPosition{
+				{Char: "R", X: 390895.94072725705, Y: 411502.57755990914, Angle: 32.319616508635505},
+				{Char: "u", X: 390915.09866447025, Y: 411514.69788753404, Angle: 32.319616508635505},
+				{Char: "e", X: 390932.650951895, Y: 411525.80239590496, Angle: 32.319616508635505},
+				{Char: " ", X: 390947.23539749923, Y: 411535.5475117123, Angle: 39.13039955650276},
+				{Char: "d", X: 390954.565872863, Y: 411541.5112882793, Angle: 39.13039955650276},
+				{Char: "e", X: 390970.6774044297, Y: 411554.6189749774, Angle: 39.13039955650276},
+				{Char: " ", X: 390985.346112274, Y: 411566.5528389861, Angle: 39.13039955650276},
+				{Char: "l", X: 390992.67658763775, Y: 411572.5166155531, Angle: 39.13039955650276},
+				{Char: "'", X: 390999.7605482409, Y: 411578.0834118507, Angle: 41.37851529552499},
+				{Char: "É", X: 391005.8234489192, Y: 411583.4245386386, Angle: 41.37851529552499},
+				{Char: "g", X: 391021.408405242, Y: 411597.15414301807, Angle: 41.37851529552499},
+				{Char: "l", X: 391036.9933615648, Y: 411610.8837473975, Angle: 41.37851529552499},
+				{Char: "i", X: 391043.0153738015, Y: 411615.931113143, Angle: 49.49715161429619},
+				{Char: "s", X: 391049.15301508526, Y: 411623.11664440186, Angle: 49.49715161429619},
+				{Char: "e", X: 391060.19427453744, Y: 411636.04299693106, Angle: 49.49715161429619},
+			},
+		},
 	}
 
 	for name, tt := range tests {
@@ -260,6 +314,517 @@ func TestGetLetterPositions(t *testing.T) {
 	}
 }
 
+func TestGetLetterPositionsAnchor(t *testing.T) {
+	tests := map[string]struct {
+		points   [][]float64
+		label    string
+		fontSize float64
+		opts     LineOptions
+		expected []LetterPosition
+		err      bool
+	}{
+		"Start": {
+			[][]float64{{0, 0}, {250, 0}, {500, 0}},
+			"Mellor",
+			float64(34),
+			LineOptions{Anchor: AnchorStart},
+			[]LetterPosition{
+				{Char: "M", X: 0, Y: 11.333333333333334, Angle: 0},
+				{Char: "e", X: 32.09, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 51, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 60.45, Y: 11.333333333333334, Angle: 0},
+				{Char: "o", X: 69.9, Y: 11.333333333333334, Angle: 0},
+				{Char: "r", X: 90.67, Y: 11.333333333333334, Angle: 0},
+			},
+			false,
+		},
+		"Center": {
+			[][]float64{{0, 0}, {250, 0}, {500, 0}},
+			"Mellor",
+			float64(34),
+			LineOptions{Anchor: AnchorCenter},
+			[]LetterPosition{
+				{Char: "M", X: 198.05, Y: 11.333333333333334, Angle: 0},
+				{Char: "e", X: 230.14000000000001, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 249.05, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 258.5, Y: 11.333333333333334, Angle: 0},
+				{Char: "o", X: 267.95, Y: 11.333333333333334, Angle: 0},
+				{Char: "r", X: 288.71999999999997, Y: 11.333333333333334, Angle: 0},
+			},
+			false,
+		},
+		"End": {
+			[][]float64{{0, 0}, {250, 0}, {500, 0}},
+			"Mellor",
+			float64(34),
+			LineOptions{Anchor: AnchorEnd},
+			[]LetterPosition{
+				{Char: "M", X: 396.1, Y: 11.333333333333334, Angle: 0},
+				{Char: "e", X: 428.19000000000005, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 447.1000000000001, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 456.55000000000007, Y: 11.333333333333334, Angle: 0},
+				{Char: "o", X: 466.00000000000006, Y: 11.333333333333334, Angle: 0},
+				{Char: "r", X: 486.77000000000004, Y: 11.333333333333334, Angle: 0},
+			},
+			false,
+		},
+		"Offset": {
+			[][]float64{{0, 0}, {250, 0}, {500, 0}},
+			"Mellor",
+			float64(34),
+			LineOptions{Anchor: AnchorOffset, Offset: 300},
+			[]LetterPosition{
+				{Char: "M", X: 300, Y: 11.333333333333334, Angle: 0},
+				{Char: "e", X: 332.09000000000003, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 351.00000000000006, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 360.45000000000005, Y: 11.333333333333334, Angle: 0},
+				{Char: "o", X: 369.90000000000003, Y: 11.333333333333334, Angle: 0},
+				{Char: "r", X: 390.67, Y: 11.333333333333334, Angle: 0},
+			},
+			false,
+		},
+		"Offset too far": {
+			[][]float64{{0, 0}, {250, 0}, {500, 0}},
+			"Mellor",
+			float64(34),
+			LineOptions{Anchor: AnchorOffset, Offset: 450},
+			nil,
+			true,
+		},
+		"Center too short": {
+			[][]float64{{0, 0}, {50, 0}},
+			"Mellor",
+			float64(34),
+			LineOptions{Anchor: AnchorCenter},
+			nil,
+			true,
+		},
+	}
+
+	for name, tt := range tests {
+		f, err := truetype.Parse(ttf.UniversBold)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opts := truetype.Options{
+			Size: tt.fontSize,
+		}
+		face := truetype.NewFace(f, &opts)
+
+		typeFace := fonts.TypeFace{
+			Size: tt.fontSize,
+			FontData: draw2d.FontData{
+				Name:   "bold",
+				Family: draw2d.FontFamilySans,
+				Style:  draw2d.FontStyleNormal,
+			},
+			Face: face,
+		}
+
+		actual, err := GetLetterPositionsWithOptions(tt.label, tt.points, typeFace, tt.opts)
+		if tt.err {
+			if !errors.Is(err, ErrLettersDontFit) {
+				t.Errorf("%v: Expected ErrLettersDontFit, Got [%v]", name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestGetLetterPositionsUpright(t *testing.T) {
+	tests := map[string]struct {
+		points      [][]float64
+		opts        LineOptions
+		expected    []LetterPosition
+		angleExpect float64
+	}{
+		"Left to right": {
+			[][]float64{{0, 0}, {500, 0}},
+			LineOptions{KeepUpright: true},
+			[]LetterPosition{
+				{Char: "M", X: 0, Y: 11.333333333333334, Angle: 0},
+				{Char: "e", X: 32.09, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 51, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 60.45, Y: 11.333333333333334, Angle: 0},
+				{Char: "o", X: 69.9, Y: 11.333333333333334, Angle: 0},
+				{Char: "r", X: 90.67, Y: 11.333333333333334, Angle: 0},
+			},
+			0,
+		},
+		"Right to left": {
+			[][]float64{{500, 0}, {0, 0}},
+			LineOptions{KeepUpright: true},
+			[]LetterPosition{
+				{Char: "M", X: 396.1, Y: 11.333333333333334, Angle: 0},
+				{Char: "e", X: 428.19000000000005, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 447.1000000000001, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 456.55000000000007, Y: 11.333333333333334, Angle: 0},
+				{Char: "o", X: 466.00000000000006, Y: 11.333333333333334, Angle: 0},
+				{Char: "r", X: 486.77000000000004, Y: 11.333333333333334, Angle: 0},
+			},
+			0,
+		},
+		"Right to left upside down": {
+			[][]float64{{500, 0}, {0, 0}},
+			LineOptions{},
+			[]LetterPosition{
+				{Char: "M", X: 500, Y: -11.333333333333334, Angle: 180},
+				{Char: "e", X: 467.90999999999997, Y: -11.33333333333333, Angle: 180},
+				{Char: "l", X: 448.99999999999994, Y: -11.333333333333327, Angle: 180},
+				{Char: "l", X: 439.54999999999995, Y: -11.333333333333327, Angle: 180},
+				{Char: "o", X: 430.09999999999997, Y: -11.333333333333325, Angle: 180},
+				{Char: "r", X: 409.33, Y: -11.333333333333323, Angle: 180},
+			},
+			180,
+		},
+		"Right to left centred": {
+			[][]float64{{500, 0}, {0, 0}},
+			LineOptions{Anchor: AnchorCenter, KeepUpright: true},
+			[]LetterPosition{
+				{Char: "M", X: 198.04999999999998, Y: 11.333333333333334, Angle: 0},
+				{Char: "e", X: 230.14, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 249.04999999999998, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 258.5, Y: 11.333333333333334, Angle: 0},
+				{Char: "o", X: 267.95, Y: 11.333333333333334, Angle: 0},
+				{Char: "r", X: 288.71999999999997, Y: 11.333333333333334, Angle: 0},
+			},
+			0,
+		},
+		"Mostly right to left": {
+			[][]float64{{500, 0}, {100, 0}, {200, -20}},
+			LineOptions{KeepUpright: true},
+			[]LetterPosition{
+				{Char: "M", X: 396.09999999999997, Y: 11.333333333333334, Angle: 0},
+				{Char: "e", X: 428.18999999999994, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 447.09999999999997, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 456.54999999999995, Y: 11.333333333333334, Angle: 0},
+				{Char: "o", X: 465.99999999999994, Y: 11.333333333333334, Angle: 0},
+				{Char: "r", X: 486.7699999999999, Y: 11.333333333333334, Angle: 0},
+			},
+			0,
+		},
+	}
+
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 34,
+	}
+	face := truetype.NewFace(f, &opts)
+
+	typeFace := fonts.TypeFace{
+		Size: 34,
+		FontData: draw2d.FontData{
+			Name:   "bold",
+			Family: draw2d.FontFamilySans,
+			Style:  draw2d.FontStyleNormal,
+		},
+		Face: face,
+	}
+
+	for name, tt := range tests {
+		actual, err := GetLetterPositionsWithOptions("Mellor", tt.points, typeFace, tt.opts)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+
+		for _, lp := range actual {
+			if lp.Angle != tt.angleExpect {
+				t.Errorf("%v: Expected angle [%v], Got [%v]", name, tt.angleExpect, lp.Angle)
+			}
+		}
+	}
+}
+
+func TestGetLetterPositionsSmooth(t *testing.T) {
+	tests := map[string]struct {
+		points   [][]float64
+		label    string
+		opts     LineOptions
+		expected []LetterPosition
+	}{
+		"Straight": {
+			[][]float64{{0, 0}, {250, 0}, {500, 0}},
+			"Mellor",
+			LineOptions{Smooth: true},
+			[]LetterPosition{
+				{Char: "M", X: 0, Y: 11.333333333333334, Angle: 0},
+				{Char: "e", X: 32.09, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 51, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 60.45, Y: 11.333333333333334, Angle: 0},
+				{Char: "o", X: 69.9, Y: 11.333333333333334, Angle: 0},
+				{Char: "r", X: 90.67, Y: 11.333333333333334, Angle: 0},
+			},
+		},
+		"Corner": {
+			[][]float64{{0, 0}, {60, 0}, {60, 200}},
+			"Mellor",
+			LineOptions{Smooth: true},
+			[]LetterPosition{
+				{Char: "M", X: 0, Y: 11.333333333333334, Angle: 0},
+				{Char: "e", X: 32.09, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 50.436099150765116, Y: 11.081328217377786, Angle: 2.882249637652848},
+				{Char: "l", X: 48.666666666666664, Y: 0.45000000000000356, Angle: 90},
+				{Char: "o", X: 48.666666666666664, Y: 9.900000000000006, Angle: 90},
+				{Char: "r", X: 48.666666666666664, Y: 30.67, Angle: 90},
+			},
+		},
+		"Corner centred": {
+			[][]float64{{0, 0}, {100, 0}, {100, 100}},
+			"Mellor",
+			LineOptions{Smooth: true, Anchor: AnchorCenter},
+			[]LetterPosition{
+				{Char: "M", X: 48.05, Y: 11.333333333333334, Angle: 0},
+				{Char: "e", X: 80.14, Y: 11.333333333333334, Angle: 0},
+				{Char: "l", X: 88.2119400669105, Y: 0.33759746314068173, Angle: 83.62514376863713},
+				{Char: "l", X: 88.66666666666667, Y: 8.5, Angle: 90},
+				{Char: "o", X: 88.66666666666667, Y: 17.94999999999999, Angle: 90},
+				{Char: "r", X: 88.66666666666667, Y: 38.72, Angle: 90},
+			},
+		},
+	}
+
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 34,
+	}
+	face := truetype.NewFace(f, &opts)
+
+	typeFace := fonts.TypeFace{
+		Size: 34,
+		FontData: draw2d.FontData{
+			Name:   "bold",
+			Family: draw2d.FontFamilySans,
+			Style:  draw2d.FontStyleNormal,
+		},
+		Face: face,
+	}
+
+	for name, tt := range tests {
+		actual, err := GetLetterPositionsWithOptions(tt.label, tt.points, typeFace, tt.opts)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestGetLetterPositionsSmoothCircle(t *testing.T) {
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 20,
+	}
+	face := truetype.NewFace(f, &opts)
+
+	typeFace := fonts.TypeFace{
+		Size: 20,
+		FontData: draw2d.FontData{
+			Name:   "bold",
+			Family: draw2d.FontFamilySans,
+			Style:  draw2d.FontStyleNormal,
+		},
+		Face:    face,
+		Spacing: 0.5,
+	}
+
+	label := "Why are there two equation expressions?"
+	circleCoords, err := textdraw.Circle([]float64{200, 200}, 140, 40)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the sum of the squared changes in angle is lowest when the rotation is spread evenly
+	jerk := func(lps []LetterPosition) float64 {
+		var j float64
+		for i := 1; i < len(lps); i++ {
+			d := math.Abs(lps[i].Angle - lps[i-1].Angle)
+			if d > 180 {
+				d = 360 - d
+			}
+			j += d * d
+		}
+		return j
+	}
+
+	segments, err := GetLetterPositionsWithOptions(label, circleCoords, typeFace, LineOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	smooth, err := GetLetterPositionsWithOptions(label, circleCoords, typeFace, LineOptions{Smooth: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if jerk(smooth) >= jerk(segments) {
+		t.Errorf("Expected smooth rotation [%v] to be more even than segment rotation [%v]", jerk(smooth), jerk(segments))
+	}
+}
+
+func TestGetLetterPositionsSmoothTangent(t *testing.T) {
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 20,
+	}
+	face := truetype.NewFace(f, &opts)
+
+	typeFace := fonts.TypeFace{
+		Size: 20,
+		FontData: draw2d.FontData{
+			Name:   "bold",
+			Family: draw2d.FontFamilySans,
+			Style:  draw2d.FontStyleNormal,
+		},
+		Face: face,
+	}
+
+	label := "Why are there two equation expressions?"
+	centre, radius := []float64{200, 200}, float64(140)
+	circleCoords, err := textdraw.Circle(centre, radius, 720)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lps, err := GetLetterPositionsWithOptions(label, circleCoords, typeFace, LineOptions{Smooth: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	charMetrics := getCharMetrics(label, typeFace)
+	if len(lps) != len(charMetrics) {
+		t.Fatalf("Expected %v letters, Got %v", len(charMetrics), len(lps))
+	}
+
+	// which way round the circle goes
+	x0, y0 := circleCoords[0][0]-centre[0], circleCoords[0][1]-centre[1]
+	x1, y1 := circleCoords[1][0]-centre[0], circleCoords[1][1]-centre[1]
+	turn := float64(90)
+	if x0*y1-y0*x1 < 0 {
+		turn = -90
+	}
+
+	height := fonts.GetFaceMetrics(typeFace).Height
+	for i, lp := range lps {
+		// undo the offset from the centre of the character to where it starts
+		half := charMetrics[i].Metrics.Advance / 2
+		a := lp.Angle * (math.Pi / 180)
+		cx := lp.X + math.Cos(a)*half + math.Sin(a)*height/3
+		cy := lp.Y + math.Sin(a)*half - math.Cos(a)*height/3
+
+		if r := math.Hypot(cx-centre[0], cy-centre[1]); math.Abs(r-radius) > 0.5 {
+			t.Errorf("%v %q: Expected the centre on the circle [%v], Got [%v]", i, lp.Char, radius, r)
+		}
+
+		tangent := math.Atan2(cy-centre[1], cx-centre[0])/(math.Pi/180) + turn
+		d := math.Mod(math.Abs(lp.Angle-tangent), 360)
+		if d > 180 {
+			d = 360 - d
+		}
+		if d > 1 {
+			t.Errorf("%v %q: Expected angle [%v], Got [%v]", i, lp.Char, tangent, lp.Angle)
+		}
+	}
+}
+
+func TestTextAlongLineMultiByte(t *testing.T) {
+	tests := map[string]struct {
+		label    string
+		fontSize float64
+		spacing  float64
+	}{
+		"Latin": {
+			"Königstraße",
+			float64(10),
+			float64(1),
+		},
+		"Combining": {
+			"Cafe\u0301 Rene\u0301",
+			float64(10),
+			float64(1),
+		},
+		"Greek": {
+			"Οδός Αθηνάς",
+			float64(10),
+			float64(0),
+		},
+		"CJK": {
+			"中山路",
+			float64(10),
+			float64(0),
+		},
+	}
+
+	lineCoords := [][]float64{{0, 0}, {100, 20}, {200, 0}, {300, 40}}
+
+	for name, tt := range tests {
+		f, err := truetype.Parse(ttf.UniversBold)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opts := truetype.Options{
+			Size: tt.fontSize,
+		}
+		face := truetype.NewFace(f, &opts)
+
+		typeFace := fonts.TypeFace{
+			Size: tt.fontSize,
+			FontData: draw2d.FontData{
+				Name:   "bold",
+				Family: draw2d.FontFamilySans,
+				Style:  draw2d.FontStyleNormal,
+			},
+			Face:    face,
+			Spacing: tt.spacing,
+		}
+
+		glyphs, err := TextAlongLine(nil, tt.label, lineCoords, typeFace)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		expected := []rune(tt.label)
+		actual := []rune{}
+		for _, g := range glyphs {
+			actual = append(actual, g.Char)
+		}
+
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%v: Expected [%q]\nActual [%q]", name, expected, actual)
+		}
+	}
+}
+
 func TestLoveHeart(t *testing.T) {
 	tests := map[string]struct {
 		dim            int
@@ -0,0 +1,127 @@
+package text
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+
+	"github.com/rockwell-uk/go-text/fonts"
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestSplitStringInTwoMeasured(t *testing.T) {
+	tests := map[string]struct {
+		label    string
+		fontSize float64
+		expected []string
+	}{
+		"Short": {
+			"High Street",
+			float64(20),
+			[]string{"High Street"},
+		},
+		"Two words": {
+			"Pilsworth Road",
+			float64(20),
+			[]string{"Pilsworth", "Road"},
+		},
+		"Wide letters": {
+			"WWW MMM iii lll",
+			float64(20),
+			[]string{"WWW", "MMM iii lll"},
+		},
+	}
+
+	for name, tt := range tests {
+		f, err := truetype.Parse(ttf.UniversBold)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		opts := truetype.Options{
+			Size: tt.fontSize,
+		}
+		face, err := fonts.WithKerning(truetype.NewFace(f, &opts), ttf.UniversBold, tt.fontSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		typeFace := fonts.TypeFace{
+			Size: tt.fontSize,
+			FontData: draw2d.FontData{
+				Name:   "bold",
+				Family: draw2d.FontFamilySans,
+				Style:  draw2d.FontStyleNormal,
+			},
+			Face: face,
+		}
+
+		actual := SplitStringInTwoMeasured(tt.label, typeFace, ShouldSplit)
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%q], Got [%q]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	tests := map[string]struct {
+		label   string
+		spacing float64
+	}{
+		"Kerned": {
+			"AVATAR",
+			float64(0),
+		},
+		"Spaced": {
+			"Mellor",
+			float64(3),
+		},
+		"Combining": {
+			"Café",
+			float64(2),
+		},
+	}
+
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 20,
+	}
+	face, err := fonts.WithKerning(truetype.NewFace(f, &opts), ttf.UniversBold, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tt := range tests {
+		typeFace := fonts.TypeFace{
+			Size: 20,
+			FontData: draw2d.FontData{
+				Name:   "bold",
+				Family: draw2d.FontFamilySans,
+				Style:  draw2d.FontStyleNormal,
+			},
+			Face:    face,
+			Spacing: tt.spacing,
+		}
+
+		lps, err := GetLetterPositions(tt.label, [][]float64{{0, 0}, {500, 0}}, typeFace)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		// the label measures from the start of its first character to the end of its last
+		last := lps[len(lps)-1]
+		expected := last.X + fonts.GetGlyphWidth(typeFace, []rune(last.Char)[0])
+
+		if actual := measure(tt.label, typeFace); math.Abs(actual-expected) > 1e-9 {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, expected, actual)
+		}
+	}
+}
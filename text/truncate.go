@@ -0,0 +1,87 @@
+package text
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+// Ellipsis is appended to labels that have been truncated.
+const Ellipsis = "…"
+
+// Truncation controls how a label that is too long is shortened.
+type Truncation int
+
+const (
+	TruncateNone Truncation = iota
+	// cut the label after any character
+	TruncateGlyph
+	// cut the label between words, or inside the first word if it doesnt fit on its own
+	TruncateWord
+)
+
+// Truncate shortens s, appending an ellipsis, until it is no wider than maxWidth
+// when laid out with tf. It returns s if it already fits, or an empty string if
+// nothing does.
+func Truncate(s string, tf fonts.TypeFace, maxWidth float64, mode Truncation) string {
+	if mode == TruncateNone || measure(s, tf) <= maxWidth {
+		return s
+	}
+
+	for _, candidate := range truncations(s, mode) {
+		if measure(candidate, tf) <= maxWidth {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// truncateAlongLine places the longest truncated version of the label that fits on the line.
+func truncateAlongLine(label string, lineCoords [][]float64, tf fonts.TypeFace, opts LineOptions, letterPositions []LetterPosition, err error) ([]LetterPosition, error) {
+	mode := opts.Truncate
+	opts.Truncate = TruncateNone
+
+	for _, candidate := range truncations(label, mode) {
+		lps, lerr := GetLetterPositionsWithOptions(candidate, lineCoords, tf, opts)
+		if lerr == nil {
+			return lps, nil
+		}
+		if !errors.Is(lerr, ErrLettersDontFit) {
+			return lps, lerr
+		}
+	}
+
+	return letterPositions, err
+}
+
+// truncations returns the shortened versions of s, longest first.
+func truncations(s string, mode Truncation) []string {
+	var t []string
+
+	switch mode {
+	case TruncateNone:
+		return t
+	case TruncateGlyph:
+		g := graphemes(s)
+		for i := len(g) - 1; i > 0; i-- {
+			prefix := strings.TrimRight(strings.Join(g[:i], ""), " ")
+			if prefix != "" && (len(t) == 0 || t[len(t)-1] != prefix+Ellipsis) {
+				t = append(t, prefix+Ellipsis)
+			}
+		}
+	case TruncateWord:
+		words := strings.Fields(s)
+		for i := len(words) - 1; i > 0; i-- {
+			t = append(t, strings.Join(words[:i], " ")+Ellipsis)
+		}
+
+		// when the first word is too long on its own, cut it after any character
+		if len(words) > 0 {
+			t = append(t, truncations(words[0], TruncateGlyph)...)
+		}
+	}
+
+	return t
+}
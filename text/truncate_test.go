@@ -0,0 +1,196 @@
+package text
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/freetype/truetype"
+	"github.com/llgcode/draw2d"
+
+	"github.com/rockwell-uk/go-text/fonts"
+	"github.com/rockwell-uk/go-text/fonts/ttf"
+)
+
+func TestGraphemes(t *testing.T) {
+	tests := map[string]struct {
+		s        string
+		expected []string
+	}{
+		"ASCII": {
+			"Road",
+			[]string{"R", "o", "a", "d"},
+		},
+		"Precomposed": {
+			"Café",
+			[]string{"C", "a", "f", "é"},
+		},
+		"Combining": {
+			"Café",
+			[]string{"C", "a", "f", "é"},
+		},
+		"Joined": {
+			"a\U0001F469\u200d\U0001F4BBb",
+			[]string{"a", "\U0001F469\u200d\U0001F4BB", "b"},
+		},
+	}
+
+	for name, tt := range tests {
+		actual := graphemes(tt.s)
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%q], Got [%q]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestTruncations(t *testing.T) {
+	tests := map[string]struct {
+		s        string
+		mode     Truncation
+		expected []string
+	}{
+		"Glyph": {
+			"Café Nord",
+			TruncateGlyph,
+			[]string{"Café Nor…", "Café No…", "Café N…", "Café…", "Caf…", "Ca…", "C…"},
+		},
+		"Word": {
+			"Rue de la Paix",
+			TruncateWord,
+			[]string{"Rue de la…", "Rue de…", "Rue…", "Ru…", "R…"},
+		},
+		"Single word": {
+			"Königstraße",
+			TruncateWord,
+			[]string{"Königstraß…", "Königstra…", "Königstr…", "Königst…", "Königs…", "König…", "Köni…", "Kön…", "Kö…", "K…"},
+		},
+		"None": {
+			"Rue de la Paix",
+			TruncateNone,
+			nil,
+		},
+	}
+
+	for name, tt := range tests {
+		actual := truncations(tt.s, tt.mode)
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%q], Got [%q]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := map[string]struct {
+		label    string
+		maxWidth float64
+		mode     Truncation
+		expected string
+	}{
+		"Fits": {
+			"Pilsworth Road",
+			400,
+			TruncateGlyph,
+			"Pilsworth Road",
+		},
+		"Glyph": {
+			"Pilsworth Road",
+			230,
+			TruncateGlyph,
+			"Pilsworth R…",
+		},
+		"Word": {
+			"Pilsworth Road",
+			230,
+			TruncateWord,
+			"Pilsworth…",
+		},
+		"Single word": {
+			"Pilsworth",
+			120,
+			TruncateWord,
+			"Pils…",
+		},
+		"Nothing fits": {
+			"Pilsworth Road",
+			10,
+			TruncateWord,
+			"",
+		},
+	}
+
+	typeFace := truncateTypeFace(t)
+
+	for name, tt := range tests {
+		actual := Truncate(tt.label, typeFace, tt.maxWidth, tt.mode)
+
+		if tt.expected != actual {
+			t.Errorf("%v: Expected [%q], Got [%q]", name, tt.expected, actual)
+		}
+	}
+}
+
+func TestGetLetterPositionsTruncated(t *testing.T) {
+	tests := map[string]struct {
+		points   [][]float64
+		opts     LineOptions
+		expected string
+	}{
+		"Glyph": {
+			[][]float64{{0, 0}, {150, 0}},
+			LineOptions{Anchor: AnchorCenter, Truncate: TruncateGlyph},
+			"Pilswo…",
+		},
+		"Word": {
+			[][]float64{{0, 0}, {200, 0}},
+			LineOptions{Anchor: AnchorCenter, Truncate: TruncateWord},
+			"Pilsworth…",
+		},
+		"Fitted": {
+			[][]float64{{0, 0}, {200, 0}},
+			LineOptions{Anchor: AnchorCenter, Truncate: TruncateWord, Fit: FitShrink, MinSize: 30, SizeStep: 2},
+			"Pilsworth…",
+		},
+	}
+
+	typeFace := truncateTypeFace(t)
+
+	for name, tt := range tests {
+		lps, _, err := GetLetterPositionsFitted("Pilsworth Road", tt.points, typeFace, tt.opts)
+		if err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+
+		var actual string
+		for _, lp := range lps {
+			actual += lp.Char
+		}
+
+		if tt.expected != actual {
+			t.Errorf("%v: Expected [%q], Got [%q]", name, tt.expected, actual)
+		}
+	}
+}
+
+func truncateTypeFace(t *testing.T) fonts.TypeFace {
+	t.Helper()
+
+	f, err := truetype.Parse(ttf.UniversBold)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := truetype.Options{
+		Size: 34,
+	}
+
+	return fonts.TypeFace{
+		Size: 34,
+		FontData: draw2d.FontData{
+			Name:   "bold",
+			Family: draw2d.FontFamilySans,
+			Style:  draw2d.FontStyleNormal,
+		},
+		Face: truetype.NewFace(f, &opts),
+	}
+}
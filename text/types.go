@@ -19,6 +19,95 @@ type LetterPosition struct {
 	Angle float64
 }
 
+// Anchor determines where along a line a label is placed.
+type Anchor int
+
+const (
+	// the label starts at the start of the line
+	AnchorStart Anchor = iota
+	// the label is centred on the midpoint of the line
+	AnchorCenter
+	// the label finishes at the end of the line
+	AnchorEnd
+	// the label starts at LineOptions.Offset along the line
+	AnchorOffset
+	// the label is centred on LineOptions.Offset along the line
+	AnchorCenterOffset
+	// the label is centred where a ray from the middle of the line at
+	// LineOptions.Angle degrees crosses it, for placing labels on rings
+	AnchorAngle
+)
+
+func (a Anchor) String() string {
+	switch a {
+	case AnchorStart:
+		return "start"
+	case AnchorCenter:
+		return "center"
+	case AnchorEnd:
+		return "end"
+	case AnchorOffset:
+		return "offset"
+	case AnchorCenterOffset:
+		return "center offset"
+	case AnchorAngle:
+		return "angle"
+	}
+
+	return fmt.Sprintf("anchor(%d)", int(a))
+}
+
+// LineOptions controls how a label is placed along a line.
+// The zero value places the label at the start of the line.
+type LineOptions struct {
+	Anchor Anchor
+	// distance along the line at which the label starts, used with AnchorOffset,
+	// or its centre, used with AnchorCenterOffset
+	Offset float64
+	// direction from the middle of the line to the centre of the label, used with AnchorAngle
+	Angle float64
+	// the line is a ring, the label can start anywhere on it and run across the join
+	Closed bool
+	// run the label the other way along the line when it would otherwise be upside down
+	KeepUpright bool
+	// place each character by its centre, rotated to the tangent of the line there interpolated
+	// across its width, rather than taking the angle of the segment it starts on
+	Smooth bool
+	// the largest change in angle (degrees) allowed between adjacent characters, 0 for no limit
+	MaxCharAngleDelta float64
+	// the largest total change in angle (degrees) allowed over any AngleWindow adjacent characters, 0 for no limit
+	MaxWindowAngleDelta float64
+	AngleWindow         int
+	// when the line is too curvy, keep trying SearchStep further along the line
+	SearchStep float64
+	// run the label parallel to the line, offset to the side of it, positive values
+	// put the label above the line (when reading it) and negative values below
+	BaselineOffset     float64
+	BaselineOffsetUnit OffsetUnit
+	// what to do when the label is too long or short for the line, used by the Fitted functions
+	Fit Fit
+	// the tightest and loosest letter spacing allowed when fitting, 0 MaxSpacing for no limit,
+	// the spacing is only tightened when MinSpacing is less than the typeface's Spacing
+	MinSpacing float64
+	MaxSpacing float64
+	// the smallest font size allowed when shrinking, and how much to reduce it by each
+	// time, SizeStep defaults to DefaultSizeStep
+	MinSize  float64
+	SizeStep float64
+	// shorten labels that are too long for the line, after any fitting
+	Truncate Truncation
+	// simplify the line before placing the label along it, the tolerance is a distance
+	// for SimplifyDouglasPeucker and an area for SimplifyVisvalingam
+	Simplify          Simplification
+	SimplifyTolerance float64
+	// smooth the line (after any simplification) before placing the label along it,
+	// SmoothingPasses defaults to 2 for SmoothChaikin and 8 for SmoothCatmullRom
+	Smoothing       Smoothing
+	SmoothingPasses int
+	// the distance between the baselines of multi line labels, as a multiple of the font height, defaults to 1
+	LineHeight float64
+}
+
 type LineData struct {
 	Pos    []float64
 	Angle  float64
@@ -0,0 +1,125 @@
+package text
+
+import (
+	"math"
+	"strings"
+
+	"github.com/rockwell-uk/go-text/fonts"
+)
+
+// WrapOptions controls how a label is wrapped onto several lines.
+type WrapOptions struct {
+	// the widest a line can be, a single word that is wider than this gets a line to itself.
+	// 0 for no limit
+	MaxWidth float64
+	// how many lines to wrap onto, more are used if the lines wont fit in MaxWidth.
+	// 0 to use as few lines as MaxWidth allows
+	Lines int
+}
+
+// Wrap splits s at its spaces onto lines of as even a width as possible, measured with tf.
+// The lines are chosen to keep the sum of the squared differences from the average width
+// as low as possible, rather than filling each line in turn.
+func Wrap(s string, tf fonts.TypeFace, opts WrapOptions) []string {
+	words := []string{}
+	for _, w := range strings.Split(s, " ") {
+		if w != "" {
+			words = append(words, w)
+		}
+	}
+	if len(words) < 2 || (opts.MaxWidth <= 0 && opts.Lines <= 1) {
+		return []string{s}
+	}
+
+	// the width of every run of words
+	widths := make([][]float64, len(words))
+	for i := range words {
+		widths[i] = make([]float64, len(words)+1)
+		for j := i + 1; j <= len(words); j++ {
+			widths[i][j] = measure(strings.Join(words[i:j], " "), tf)
+		}
+	}
+
+	maxWidth := opts.MaxWidth
+	if maxWidth <= 0 {
+		maxWidth = math.Inf(1)
+	}
+
+	lines := opts.Lines
+	if needed := greedyLines(widths, maxWidth); needed > lines {
+		lines = needed
+	}
+	if lines > len(words) {
+		lines = len(words)
+	}
+
+	breaks := balance(widths, lines, maxWidth)
+
+	wrapped := []string{}
+	for l := 0; l < len(breaks)-1; l++ {
+		wrapped = append(wrapped, strings.Join(words[breaks[l]:breaks[l+1]], " "))
+	}
+
+	return wrapped
+}
+
+// greedyLines counts the lines needed when each one is filled before starting the next.
+func greedyLines(widths [][]float64, maxWidth float64) int {
+	lines := 1
+	start := 0
+
+	for j := 2; j <= len(widths); j++ {
+		if widths[start][j] > maxWidth {
+			lines++
+			start = j - 1
+		}
+	}
+
+	return lines
+}
+
+// balance breaks the words onto exactly n lines, returning the index of the first word
+// on each line and the number of words.
+func balance(widths [][]float64, n int, maxWidth float64) []int {
+	words := len(widths)
+	target := widths[0][words] / float64(n)
+
+	cost := func(i, j int) float64 {
+		w := widths[i][j]
+		if w > maxWidth && j-i > 1 {
+			return math.Inf(1)
+		}
+		return (w - target) * (w - target)
+	}
+
+	// best[k][j] is the lowest cost of putting the first j words on k lines
+	best := make([][]float64, n+1)
+	from := make([][]int, n+1)
+	for k := range best {
+		best[k] = make([]float64, words+1)
+		from[k] = make([]int, words+1)
+		for j := range best[k] {
+			best[k][j] = math.Inf(1)
+		}
+	}
+	best[0][0] = 0
+
+	for k := 1; k <= n; k++ {
+		for j := k; j <= words; j++ {
+			for i := k - 1; i < j; i++ {
+				if c := best[k-1][i] + cost(i, j); c < best[k][j] {
+					best[k][j] = c
+					from[k][j] = i
+				}
+			}
+		}
+	}
+
+	breaks := make([]int, n+1)
+	breaks[n] = words
+	for k := n; k > 0; k-- {
+		breaks[k-1] = from[k][breaks[k]]
+	}
+
+	return breaks
+}
@@ -0,0 +1,65 @@
+package text
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	tests := map[string]struct {
+		s        string
+		opts     WrapOptions
+		expected []string
+	}{
+		"Two lines": {
+			"Ashton under Lyne",
+			WrapOptions{Lines: 2},
+			[]string{"Ashton", "under Lyne"},
+		},
+		"Three lines": {
+			"Newcastle upon Tyne and Wear",
+			WrapOptions{Lines: 3},
+			[]string{"Newcastle", "upon Tyne", "and Wear"},
+		},
+		"Max width": {
+			"Newcastle upon Tyne and Wear",
+			WrapOptions{MaxWidth: 250},
+			[]string{"Newcastle", "upon Tyne", "and Wear"},
+		},
+		"Four lines": {
+			"Newcastle upon Tyne and Wear",
+			WrapOptions{MaxWidth: 250, Lines: 4},
+			[]string{"Newcastle", "upon", "Tyne and", "Wear"},
+		},
+		"Uneven words": {
+			"The Royal Exchange Theatre",
+			WrapOptions{Lines: 2},
+			[]string{"The Royal", "Exchange Theatre"},
+		},
+		"Words wider than the max width": {
+			"Manchester Piccadilly",
+			WrapOptions{MaxWidth: 100},
+			[]string{"Manchester", "Piccadilly"},
+		},
+		"One word": {
+			"Manchester",
+			WrapOptions{Lines: 2},
+			[]string{"Manchester"},
+		},
+		"No limits": {
+			"Manchester Piccadilly",
+			WrapOptions{},
+			[]string{"Manchester Piccadilly"},
+		},
+	}
+
+	typeFace := ringTypeFace(t)
+
+	for name, tt := range tests {
+		actual := Wrap(tt.s, typeFace, tt.opts)
+
+		if !reflect.DeepEqual(tt.expected, actual) {
+			t.Errorf("%v: Expected [%+v]\nActual [%+v]", name, tt.expected, actual)
+		}
+	}
+}